@@ -0,0 +1,73 @@
+package crossword
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Alphabet defines which characters may be used in words and how they are upper-cased.
+type Alphabet struct {
+	// Letters reports whether a character may be used in a word. Any other characters (except
+	// spaces) are removed from words unless WithKeepSpecialCharacters is used.
+	Letters func(r rune) bool
+
+	// Case is used to upper-case words in languages with special casing rules e.g. unicode.TurkishCase.
+	// If nil the standard unicode mapping is used.
+	Case unicode.SpecialCase
+}
+
+var (
+	// AlphabetUnicode allows letters and numbers from any language. This is the default.
+	AlphabetUnicode = Alphabet{
+		Letters: func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+		},
+	}
+
+	// AlphabetLatin allows only the unaccented letters A-Z and the numbers 0-9.
+	AlphabetLatin = Alphabet{
+		Letters: func(r rune) bool {
+			return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		},
+	}
+
+	// AlphabetGreek allows Greek letters and the numbers 0-9.
+	AlphabetGreek = Alphabet{
+		Letters: func(r rune) bool {
+			return unicode.Is(unicode.Greek, r) || (r >= '0' && r <= '9')
+		},
+	}
+
+	// AlphabetCyrillic allows Cyrillic letters and the numbers 0-9.
+	AlphabetCyrillic = Alphabet{
+		Letters: func(r rune) bool {
+			return unicode.Is(unicode.Cyrillic, r) || (r >= '0' && r <= '9')
+		},
+	}
+
+	// AlphabetTurkish allows Latin letters and numbers, upper-casing with the Turkish rules for dotted
+	// and dotless I.
+	AlphabetTurkish = Alphabet{
+		Letters: func(r rune) bool {
+			return unicode.Is(unicode.Latin, r) || (r >= '0' && r <= '9')
+		},
+		Case: unicode.TurkishCase,
+	}
+)
+
+// strip removes any characters that are not in the alphabet, other than spaces.
+func (a Alphabet) strip(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || a.Letters(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func (a Alphabet) toUpper(s string) string {
+	if a.Case != nil {
+		return strings.ToUpperSpecial(a.Case, s)
+	}
+	return strings.ToUpper(s)
+}
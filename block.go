@@ -0,0 +1,291 @@
+package crossword
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+const (
+	defaultBlockRatio     = 1.0 / 6
+	defaultFillNodeBudget = 20000
+)
+
+// ErrGridNotFilled is returned by BlockGenerator.GenerateContext and Autofill when the grid
+// could not be filled from the words.
+var ErrGridNotFilled = errors.New("grid could not be filled from the words")
+
+// ErrUnsupportedOption is returned by BlockGenerator.GenerateContext when given WithPinned or
+// WithMask, which cannot be used with block patterns.
+var ErrUnsupportedOption = errors.New("option is not supported by the block generator")
+
+// WithBlockRatio sets the proportion of the grid's cells the BlockGenerator turns into blocks.
+// The default is one in six, similar to a standard 15x15 puzzle.
+func WithBlockRatio(ratio float64) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.blockRatio = &ratio
+	}
+}
+
+// WithMaxWordLength adds blocks to the BlockGenerator's patterns until no word is longer than
+// the given length. Zero means words may span the grid.
+func WithMaxWordLength(length int) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.maxWordLength = length
+	}
+}
+
+func NewBlockGenerator(gridSize int) *BlockGenerator {
+	return NewRectBlockGenerator(gridSize, gridSize)
+}
+
+// NewRectBlockGenerator creates a block generator for a grid with the given number of columns (width) and rows (height).
+func NewRectBlockGenerator(width, height int) *BlockGenerator {
+	return &BlockGenerator{width: width, height: height}
+}
+
+var _ LayoutGenerator = &BlockGenerator{}
+
+// BlockGenerator creates American style crosswords where every open cell is part of both an across
+// and a down word, the blocks are symmetrical under a 180° rotation and every word is at least
+// three letters long. Each attempt builds a new random block pattern and fills it from the words,
+// so the word list needs to be much larger than the number of words in the grid.
+//
+// The blocks are the empty cells of the generated Grid. Placements are numbered using the
+// conventional scheme where an across and a down word starting in the same cell share a number.
+// Attempts are always run one after another and WithNodeBudget limits the search for each of them.
+//
+// Since the whole grid is always filled and the first fill found is used, WithConcurrency,
+// WithAllAttempts, WithCrop, WithCentre, WithScorer and WithBranching have no effect. WithPinned
+// and WithMask are not supported and return ErrUnsupportedOption.
+type BlockGenerator struct {
+	width  int
+	height int
+}
+
+func (b *BlockGenerator) Generate(words []Word, attempts int, opts ...GeneratorOpt) *Crossword {
+	cw, _ := b.GenerateContext(context.Background(), words, attempts, opts...)
+	return cw
+}
+
+// GenerateContext returns the first fill found, checking the context between each search node.
+// If no pattern could be filled the crossword is nil and the error is ErrGridNotFilled, or the
+// reason generation stopped if the context was cancelled or the time budget was exceeded.
+// Grids narrower or shorter than three cells cannot hold any words so they also return
+// ErrGridNotFilled.
+func (b *BlockGenerator) GenerateContext(ctx context.Context, words []Word, attempts int, opts ...GeneratorOpt) (*Crossword, error) {
+	if b.width < minSlotLength || b.height < minSlotLength {
+		return nil, fmt.Errorf("%w: the grid must be at least %dx%d but is %dx%d", ErrGridNotFilled, minSlotLength, minSlotLength, b.width, b.height)
+	}
+	options := resolveGeneratorOptions(opts)
+	if len(options.pinned) > 0 {
+		return nil, fmt.Errorf("%w: WithPinned", ErrUnsupportedOption)
+	}
+	if options.mask != nil {
+		return nil, fmt.Errorf("%w: WithMask", ErrUnsupportedOption)
+	}
+	if options.timeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, options.timeBudget, ErrTimeBudgetExceeded)
+		defer cancel()
+	}
+
+	words = slices.Clone(words)
+	prepareWords(words, options)
+	inputWords := slices.Clone(words)
+
+	// required words are tried first in every slot they fit
+	slices.SortStableFunc(words, func(a, b Word) int {
+		if a.Required == b.Required {
+			return 0
+		}
+		if a.Required {
+			return -1
+		}
+		return 1
+	})
+
+	for range attempts {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		blocks := blockPattern(b.width, b.height, b.blockRatio(options), options.maxWordLength, options.rand)
+		if blocks == nil {
+			continue
+		}
+		shuffleUnrequired(words, options.rand)
+
+		f := newFiller(Template{Blocks: blocks}, words, b.nodeBudget(options))
+		if !f.fill(ctx) {
+			continue
+		}
+		cw := f.crossword()
+		if options.seed != nil {
+			seed := *options.seed
+			cw.Seed = &seed
+		}
+		return cw, checkRequiredWords(cw, inputWords)
+	}
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return nil, ErrGridNotFilled
+}
+
+func (b *BlockGenerator) blockRatio(options *generatorOpts) float64 {
+	if options.blockRatio != nil {
+		return *options.blockRatio
+	}
+	return defaultBlockRatio
+}
+
+func (b *BlockGenerator) nodeBudget(options *generatorOpts) int {
+	if options.nodeBudget > 0 {
+		return options.nodeBudget
+	}
+	return defaultFillNodeBudget
+}
+
+// shuffleUnrequired shuffles the words while keeping the required words at the start.
+func shuffleUnrequired(words []Word, rng *rand.Rand) {
+	required := slices.IndexFunc(words, func(w Word) bool {
+		return !w.Required
+	})
+	if required == -1 {
+		required = len(words)
+	}
+	for _, part := range [][]Word{words[:required], words[required:]} {
+		rng.Shuffle(len(part), func(i, j int) {
+			part[i], part[j] = part[j], part[i]
+		})
+	}
+}
+
+// blockPattern randomly adds symmetrical pairs of blocks to an open grid until the given ratio
+// of cells are blocked and no word is longer than maxLength. Blocks are only added where every
+// word remains at least minSlotLength long and the open cells stay connected. Since the blocks
+// added early on can make a pattern impossible to complete, it starts again from an open grid
+// a few times before giving up and returning nil. blocks[y][x] is true for blocked cells.
+func blockPattern(width, height int, ratio float64, maxLength int, rng *rand.Rand) [][]bool {
+	if maxLength <= 0 {
+		maxLength = max(width, height)
+	}
+	for range 20 {
+		if blocks := tryBlockPattern(width, height, int(ratio*float64(width*height)), maxLength, rng); blocks != nil {
+			return blocks
+		}
+	}
+	return nil
+}
+
+func tryBlockPattern(width, height int, target int, maxLength int, rng *rand.Rand) [][]bool {
+	blocks := make([][]bool, height)
+	for y := range height {
+		blocks[y] = make([]bool, width)
+	}
+
+	numBlocks := 0
+	for range 10 * width * height {
+		long := longSlots(blocks, maxLength)
+		if numBlocks >= target && len(long) == 0 && validBlockPattern(blocks) {
+			return blocks
+		}
+
+		var x, y int
+		if len(long) > 0 {
+			// break up a word that is too long, leaving words of at least the minimum length either side
+			sl := long[rng.IntN(len(long))]
+			if sl.Length > 2*minSlotLength {
+				x, y = sl.cell(minSlotLength + rng.IntN(sl.Length-2*minSlotLength))
+			} else {
+				x, y = sl.cell(rng.IntN(sl.Length))
+			}
+		} else {
+			x, y = rng.IntN(width), rng.IntN(height)
+		}
+		mirrorX, mirrorY := width-1-x, height-1-y
+		if blocks[y][x] {
+			continue
+		}
+
+		blocks[y][x], blocks[mirrorY][mirrorX] = true, true
+		if !validBlockPattern(blocks) {
+			blocks[y][x], blocks[mirrorY][mirrorX] = false, false
+			continue
+		}
+		numBlocks++
+		if x != mirrorX || y != mirrorY {
+			numBlocks++
+		}
+	}
+	return nil
+}
+
+// longSlots returns the slots longer than maxLength.
+func longSlots(blocks [][]bool, maxLength int) []slot {
+	return slices.DeleteFunc(findSlots(blocks, minSlotLength), func(sl slot) bool {
+		return sl.Length <= maxLength
+	})
+}
+
+// validBlockPattern checks every open cell is part of an across and down word of at least
+// minSlotLength, and that all the open cells are connected.
+func validBlockPattern(blocks [][]bool) bool {
+	height := len(blocks)
+	width := len(blocks[0])
+
+	open := 0
+	startX, startY := -1, -1
+	for y := range height {
+		for x := range width {
+			if blocks[y][x] {
+				continue
+			}
+			open++
+			startX, startY = x, y
+			if runLength(blocks, x, y, 1, 0) < minSlotLength || runLength(blocks, x, y, 0, 1) < minSlotLength {
+				return false
+			}
+		}
+	}
+	if open == 0 {
+		return false
+	}
+
+	// flood fill from any open cell should reach every other open cell
+	seen := make([][]bool, height)
+	for y := range height {
+		seen[y] = make([]bool, width)
+	}
+	stack := [][2]int{{startX, startY}}
+	seen[startY][startX] = true
+	reached := 0
+	for len(stack) > 0 {
+		x, y := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		reached++
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := x+d[0], y+d[1]
+			if nx < 0 || ny < 0 || nx >= width || ny >= height || blocks[ny][nx] || seen[ny][nx] {
+				continue
+			}
+			seen[ny][nx] = true
+			stack = append(stack, [2]int{nx, ny})
+		}
+	}
+	return reached == open
+}
+
+// runLength is the number of open cells in the run through x, y in the direction dx, dy.
+func runLength(blocks [][]bool, x, y, dx, dy int) int {
+	length := 1
+	for nx, ny := x-dx, y-dy; nx >= 0 && ny >= 0 && !blocks[ny][nx]; nx, ny = nx-dx, ny-dy {
+		length++
+	}
+	for nx, ny := x+dx, y+dy; nx < len(blocks[0]) && ny < len(blocks) && !blocks[ny][nx]; nx, ny = nx+dx, ny+dy {
+		length++
+	}
+	return length
+}
@@ -0,0 +1,88 @@
+package crossword
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockGenerator_Generate(t *testing.T) {
+	words := []Word{
+		{Word: "bat", Clue: "flying mammal"},
+		{Word: "ore"},
+		{Word: "wed"},
+		{Word: "bow"},
+		{Word: "are"},
+		{Word: "ted"},
+		{Word: "xylophone"},
+		{Word: "zz"},
+	}
+	cw, err := NewBlockGenerator(3).GenerateContext(context.Background(), words, 1, WithBlockRatio(0), WithSeed(1))
+	require.NoError(t, err)
+	require.Contains(t, []string{"BAT\nORE\nWED\n", "BOW\nARE\nTED\n"}, RenderText(cw, WithAllSolved(true)))
+	require.Len(t, cw.Words, 6)
+
+	// across and down words starting in the same cell share a number
+	var ids []string
+	for _, pl := range cw.Words {
+		ids = append(ids, pl.ClueID())
+	}
+	require.ElementsMatch(t, []string{"A1", "D1", "D2", "D3", "A4", "A5"}, ids)
+}
+
+func TestBlockGenerator_GenerateContext(t *testing.T) {
+	t.Run("grid cannot be filled", func(t *testing.T) {
+		cw, err := NewBlockGenerator(3).GenerateContext(context.Background(), []Word{{Word: "bat"}, {Word: "ore"}}, 2, WithBlockRatio(0))
+		require.ErrorIs(t, err, ErrGridNotFilled)
+		require.Nil(t, cw)
+	})
+	t.Run("grid too small", func(t *testing.T) {
+		for _, size := range []int{0, 1, 2} {
+			cw, err := NewBlockGenerator(size).GenerateContext(context.Background(), []Word{{Word: "at"}, {Word: "a"}}, 1, WithBlockRatio(0))
+			require.ErrorIs(t, err, ErrGridNotFilled)
+			require.Nil(t, cw)
+		}
+		_, err := NewRectBlockGenerator(5, 2).GenerateContext(context.Background(), []Word{{Word: "bat"}}, 1)
+		require.ErrorIs(t, err, ErrGridNotFilled)
+	})
+	t.Run("unsupported options", func(t *testing.T) {
+		for _, opt := range []GeneratorOpt{
+			WithPinned(Placement{Word: word("bat")}),
+			WithMask(MaskFromString("###\n###\n##.")),
+		} {
+			cw, err := NewBlockGenerator(3).GenerateContext(context.Background(), []Word{{Word: "bat"}}, 1, opt)
+			require.ErrorIs(t, err, ErrUnsupportedOption)
+			require.Nil(t, cw)
+		}
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewBlockGenerator(3).GenerateContext(ctx, []Word{{Word: "bat"}}, 1)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestBlockPattern(t *testing.T) {
+	for seed := range uint64(10) {
+		blocks := blockPattern(15, 15, defaultBlockRatio, 9, newSeededRand(seed))
+		require.NotNil(t, blocks)
+		require.True(t, validBlockPattern(blocks))
+		require.True(t, validBlockPattern(blockPattern(3, 3, 0, 0, newSeededRand(seed))))
+
+		numBlocks := 0
+		for y := range blocks {
+			for x := range blocks[y] {
+				require.Equal(t, blocks[y][x], blocks[14-y][14-x], "blocks should be symmetrical")
+				if blocks[y][x] {
+					numBlocks++
+				}
+			}
+		}
+		require.GreaterOrEqual(t, numBlocks, 15*15/6)
+		for _, sl := range findSlots(blocks, minSlotLength) {
+			require.LessOrEqual(t, sl.Length, 9)
+		}
+	}
+}
@@ -5,11 +5,18 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 type Cell struct {
 	Char    rune
 	CharIdx int
+
+	// Circled cells are marked with a circle, usually because their letters are part of a theme.
+	Circled bool
+
+	// Rebus is the full answer for a cell containing more than one character. Char is the first of them.
+	Rebus string
 }
 
 func (c Cell) String() string {
@@ -21,15 +28,33 @@ func (c Cell) Empty() bool {
 }
 
 func NewGrid(size int) Grid {
-	grid := make(Grid, size)
-	for y := range size {
-		grid[y] = make([]Cell, size)
+	return NewRectGrid(size, size)
+}
+
+// NewRectGrid creates an empty grid with the given number of columns (width) and rows (height).
+func NewRectGrid(width, height int) Grid {
+	grid := make(Grid, height)
+	for y := range height {
+		grid[y] = make([]Cell, width)
 	}
 	return grid
 }
 
 type Grid [][]Cell
 
+// Width is the number of columns in the grid.
+func (g Grid) Width() int {
+	if len(g) == 0 {
+		return 0
+	}
+	return len(g[0])
+}
+
+// Height is the number of rows in the grid.
+func (g Grid) Height() int {
+	return len(g)
+}
+
 type Placement struct {
 	ID       int
 	Word     Word
@@ -51,6 +76,19 @@ func (p Placement) ClueID() string {
 	return fmt.Sprintf("A%s", label)
 }
 
+// cell returns the grid coordinates of the character at charIdx.
+func (p Placement) cell(charIdx int) (x, y int) {
+	if p.Vertical {
+		return p.X, p.Y + charIdx
+	}
+	return p.X + charIdx, p.Y
+}
+
+// end returns the grid coordinates of the last character.
+func (p Placement) end() (x, y int) {
+	return p.cell(p.Word.Len() - 1)
+}
+
 type Word struct {
 	Word  string
 	Clue  string
@@ -63,11 +101,39 @@ type Word struct {
 	// CharacterHints allows subset of characters to be revealed (e.g. []int{0} would reveal
 	// the first char of a word by default)
 	CharacterHints []int
+
+	// Original is the spelling of the word before it was normalized (see WithNormalizer) or empty
+	// if normalization did not change it. It is shown in place of the grid letters when rendering
+	// with WithOriginalSpelling, provided it has the same number of characters as the word.
+	// LettersCounts is based on the letters in the grid rather than the original spelling, so
+	// STRAßE normalized to STRASSE is (7).
+	Original string
+
+	// Required words are placed before any others. If a required word cannot be placed
+	// GenerateContext returns ErrRequiredWordsNotPlaced.
+	Required bool
+
+	// Priority increases the weight of the word when comparing generated layouts i.e. a word
+	// with priority 2 is worth as much as 3 words with no priority. Negative values are ignored.
+	Priority int
+}
+
+// Len is the number of characters in the word i.e. the number of cells it occupies.
+func (w Word) Len() int {
+	return utf8.RuneCountInString(w.Word)
+}
+
+func (w Word) chars() []rune {
+	return []rune(w.Word)
+}
+
+func (w Word) weight() int {
+	return 1 + max(0, w.Priority)
 }
 
 func (w Word) LetterCountStr() string {
 	if len(w.LettersCounts) == 0 {
-		return fmt.Sprintf("%d", len(w.Word))
+		return fmt.Sprintf("%d", w.Len())
 	}
 
 	parts := make([]string, len(w.LettersCounts))
@@ -82,6 +148,53 @@ type Crossword struct {
 	Grid       Grid
 	Words      []Placement
 	TotalScore int
+
+	// Seed is the seed used to generate the crossword. Passing it to WithSeed along with the same
+	// words will regenerate an identical crossword. It is nil if WithRand was used.
+	Seed *uint64
+
+	// Mask is the mask given to WithMask when the crossword was generated. Cells outside the mask are
+	// not part of the puzzle and are left blank by the renderers.
+	Mask Mask
+
+	// Metadata describes the puzzle. It is not set by the generators but is kept by the file formats
+	// that support it.
+	Metadata Metadata
+
+	// Report describes the outcome of generation. It is nil if the crossword was not generated.
+	Report *GenerationReport
+}
+
+// betterThan compares crosswords by the number of required words placed, then the number
+// of words placed weighted by their priority, then the total score.
+func (cw *Crossword) betterThan(other *Crossword) bool {
+	required, coverage := cw.coverage()
+	otherRequired, otherCoverage := other.coverage()
+	if required != otherRequired {
+		return required > otherRequired
+	}
+	if coverage != otherCoverage {
+		return coverage > otherCoverage
+	}
+	return cw.TotalScore > other.TotalScore
+}
+
+func (cw *Crossword) coverage() (required int, weighted int) {
+	for _, pl := range cw.Words {
+		if pl.Word.Required {
+			required++
+		}
+		weighted += pl.Word.weight()
+	}
+	return required, weighted
+}
+
+// Metadata holds the details of a puzzle stored by file formats such as .puz.
+type Metadata struct {
+	Title     string
+	Author    string
+	Copyright string
+	Notes     string
 }
 
 func (cw *Crossword) Solve() {
@@ -90,15 +203,174 @@ func (cw *Crossword) Solve() {
 	}
 }
 
+// Crop removes any empty rows and columns around the words and updates the placements to match.
+func (cw *Crossword) Crop() {
+	minX, minY, maxX, maxY, ok := cw.bounds()
+	if !ok {
+		return
+	}
+	cw.translate(-minX, -minY, maxX-minX+1, maxY-minY+1)
+}
+
+// Centre moves the words to the middle of the grid without changing its size.
+func (cw *Crossword) Centre() {
+	minX, minY, maxX, maxY, ok := cw.bounds()
+	if !ok {
+		return
+	}
+	width, height := cw.Grid.Width(), cw.Grid.Height()
+	cw.translate(
+		(width-(maxX-minX+1))/2-minX,
+		(height-(maxY-minY+1))/2-minY,
+		width,
+		height,
+	)
+}
+
+// bounds finds the smallest box containing every non-empty cell. ok is false if the grid is empty.
+func (cw *Crossword) bounds() (minX, minY, maxX, maxY int, ok bool) {
+	minX, minY = cw.Grid.Width(), cw.Grid.Height()
+	maxX, maxY = -1, -1
+	for y := range cw.Grid {
+		for x, cell := range cw.Grid[y] {
+			if cell.Empty() {
+				continue
+			}
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+	}
+	return minX, minY, maxX, maxY, maxX >= 0
+}
+
+// translate moves every cell and placement by dx, dy into a new grid of the given size.
+// Any cells falling outside the new grid are discarded.
+func (cw *Crossword) translate(dx, dy, width, height int) {
+	grid := NewRectGrid(width, height)
+	for y := range cw.Grid {
+		for x, cell := range cw.Grid[y] {
+			if x+dx >= 0 && x+dx < width && y+dy >= 0 && y+dy < height {
+				grid[y+dy][x+dx] = cell
+			}
+		}
+	}
+	cw.Grid = grid
+	cw.Mask = cw.Mask.translate(dx, dy, width, height)
+	for k := range cw.Words {
+		cw.Words[k].X += dx
+		cw.Words[k].Y += dy
+	}
+}
+
+// cellText is the text shown in a cell. If originalSpelling is true and the cell belongs to a
+// word with an original spelling of the same length, the original character is used.
+func (cw *Crossword) cellText(cellX, cellY int, originalSpelling bool) string {
+	if originalSpelling {
+		for _, pl := range cw.CellPlacements(cellX, cellY) {
+			original := []rune(pl.Word.Original)
+			if len(original) == 0 || len(original) != pl.Word.Len() {
+				continue
+			}
+			// only one of the offsets can be non-zero depending on the direction of the word
+			return string(original[cellX-pl.X+cellY-pl.Y])
+		}
+	}
+	if cw.Grid[cellY][cellX].Rebus != "" {
+		return cw.Grid[cellY][cellX].Rebus
+	}
+	return cw.Grid[cellY][cellX].String()
+}
+
+// revealed checks if the cell should be shown because one of its words is solved or the
+// character is a hint.
+func (cw *Crossword) revealed(cellX, cellY int) bool {
+	for _, pl := range cw.CellPlacements(cellX, cellY) {
+		if pl.Solved || slices.Contains(pl.Word.CharacterHints, cw.Grid[cellY][cellX].CharIdx) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocks returns the cells which are not part of any word i.e. blocks[y][x] is true for empty
+// cells and cells outside the mask.
+func (cw *Crossword) blocks() [][]bool {
+	blocks := make([][]bool, cw.Grid.Height())
+	for y := range cw.Grid {
+		blocks[y] = make([]bool, cw.Grid.Width())
+		for x, cell := range cw.Grid[y] {
+			blocks[y][x] = cell.Empty() || !cw.Mask.Allowed(x, y)
+		}
+	}
+	return blocks
+}
+
+// placeSlots sets the words to the letters of the grid in each slot, using the conventional numbering.
+// The slots must be in the order returned by findSlots and the clues in the same order. See
+// markRevealed for the use of revealed.
+func (cw *Crossword) placeSlots(slots []slot, clues []string, revealed func(x, y int) bool) {
+	numbers := slotNumbers(slots)
+	cw.Words = make([]Placement, len(slots))
+	for k, sl := range slots {
+		chars := make([]rune, sl.Length)
+		for charIdx := range sl.Length {
+			x, y := sl.cell(charIdx)
+			chars[charIdx] = cw.Grid[y][x].Char
+			cw.Grid[y][x].CharIdx = charIdx
+		}
+		cw.Words[k] = Placement{
+			ID:       numbers[k],
+			Word:     Word{Word: string(chars), Clue: clues[k], LettersCounts: []int{sl.Length}},
+			X:        sl.X,
+			Y:        sl.Y,
+			Vertical: sl.Vertical,
+		}
+	}
+	cw.markRevealed(revealed)
+}
+
+// markRevealed restores the solved words and hints from the cells filled in by a solver. Words with
+// every cell revealed are solved and any other revealed cells which are not part of a solved word
+// become character hints.
+func (cw *Crossword) markRevealed(revealed func(x, y int) bool) {
+	solved := map[[2]int]bool{}
+	for k, pl := range cw.Words {
+		cw.Words[k].Solved = true
+		for charIdx := range pl.Word.Len() {
+			if !revealed(pl.cell(charIdx)) {
+				cw.Words[k].Solved = false
+				break
+			}
+		}
+		if cw.Words[k].Solved {
+			for charIdx := range pl.Word.Len() {
+				x, y := pl.cell(charIdx)
+				solved[[2]int{x, y}] = true
+			}
+		}
+	}
+	for k, pl := range cw.Words {
+		if pl.Solved {
+			continue
+		}
+		for charIdx := range pl.Word.Len() {
+			if x, y := pl.cell(charIdx); revealed(x, y) && !solved[[2]int{x, y}] {
+				cw.Words[k].Word.CharacterHints = append(cw.Words[k].Word.CharacterHints, charIdx)
+				cw.Grid[y][x].CharIdx = charIdx
+			}
+		}
+	}
+}
+
 func (cw *Crossword) CellPlacements(cellX, cellY int) []Placement {
 	var placements []Placement
 	for _, pl := range cw.Words {
 		if pl.Vertical {
-			if pl.X == cellX && cellY >= pl.Y && cellY < pl.Y+len(pl.Word.Word) {
+			if pl.X == cellX && cellY >= pl.Y && cellY < pl.Y+pl.Word.Len() {
 				placements = append(placements, pl)
 			}
 		} else {
-			if pl.Y == cellY && cellX >= pl.X && cellX < pl.X+len(pl.Word.Word) {
+			if pl.Y == cellY && cellX >= pl.X && cellX < pl.X+pl.Word.Len() {
 				placements = append(placements, pl)
 			}
 		}
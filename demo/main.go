@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/warmans/go-crossword/v2"
+	"image/png"
 	"strconv"
 	"strings"
 
@@ -108,19 +109,17 @@ func renderDataURL(cfg *Data) (string, error) {
 	if cw == nil {
 		return "", fmt.Errorf("crossword was not generated")
 	}
-	canvas, err := crossword.RenderPNG(
+	buff := &bytes.Buffer{}
+	err = crossword.WriteImage(
+		buff,
 		cw,
 		parseIntOrDefault(cfg.ImageWidth, 1000),
 		parseIntOrDefault(cfg.ImageHeight, 1000),
+		png.Encode,
 		crossword.WithAllSolved(cfg.ShowWords == "true"),
 	)
 	if err != nil {
-		panic("failed to create canvas: " + err.Error())
-	}
-
-	buff := &bytes.Buffer{}
-	if err := canvas.EncodePNG(buff); err != nil {
-		panic("failed to encode image: " + err.Error())
+		panic("failed to render image: " + err.Error())
 	}
 
 	return fmt.Sprintf("data:image/png;base64,%s", base64.StdEncoding.EncodeToString(buff.Bytes())), nil
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"image/color"
+	"image/png"
 	"os"
 	"strconv"
 
@@ -37,11 +38,22 @@ func main() {
 	cw := crossword.Generate(25, words, attempts, crossword.WithAllAttempts(true))
 	fmt.Print(crossword.RenderText(cw, crossword.WithAllSolved(solveAll)))
 	fmt.Printf("INPUT WORDS: %d OUTPUT WORDS: %d TOTAL SCORE: %d\n", len(words), len(cw.Words), cw.TotalScore)
+	for _, v := range cw.Report.Unplaced {
+		fmt.Printf("UNPLACED: %s (%s)\n", v.Word.Word, v.Reason)
+	}
 
-	canvas, err := crossword.RenderPNG(
+	out, err := os.Create(fmt.Sprintf("example/simple/crossword-%d.png", attempts))
+	if err != nil {
+		panic(err.Error())
+	}
+	defer out.Close()
+
+	err = crossword.WriteImage(
+		out,
 		cw,
 		1500,
 		1500,
+		png.Encode,
 		crossword.WithRandomSolved(),
 		crossword.WithBorder(50),
 		crossword.WithBackgroundColor(color.RGBA{R: 30, G: 30, B: 50, A: 255}),
@@ -55,7 +67,4 @@ func main() {
 	if err != nil {
 		panic(err.Error())
 	}
-	if err := canvas.SavePNG(fmt.Sprintf("example/simple/crossword-%d.png", attempts)); err != nil {
-		panic(err.Error())
-	}
 }
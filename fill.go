@@ -0,0 +1,509 @@
+package crossword
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidTemplate is returned by ParseTemplate and Autofill when the template is empty, its rows
+// are not all the same length, its Grid is not the same size as its Blocks, it has open cells which
+// are not part of a word or it has a letter which cannot be placed in the grid.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// minSlotLength is the shortest run of open cells that forms a word in a blocked grid.
+const minSlotLength = 3
+
+// WithMinScore excludes dictionary entries scoring less than the given score from Autofill.
+func WithMinScore(score int) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.minScore = &score
+	}
+}
+
+// DictionaryEntry is a candidate answer for Autofill. Entries with a higher Score are tried first.
+type DictionaryEntry struct {
+	Word  Word
+	Score int
+}
+
+// Template is the pattern of a blocked grid to be filled by Autofill. Every open cell must be part of
+// an across or down word of at least three cells. A single open cell between blocks is allowed in
+// the other direction but two are not, since they would form a word too short to fill.
+type Template struct {
+	// Blocks marks the cells that are not part of any word i.e. Blocks[y][x] is true for a blocked cell.
+	Blocks [][]bool
+	// Grid optionally holds the letters of a partially filled grid. Any non-empty cells are kept
+	// and the words crossing them must match. It must be the same size as Blocks or nil.
+	Grid Grid
+}
+
+// ParseTemplate creates a template from rows of text where '#' is a block, '.' is an open cell
+// and any other character is a letter already in the grid.
+func ParseTemplate(s string) (Template, error) {
+	if strings.TrimSpace(s) == "" {
+		return Template{}, fmt.Errorf("%w: template has no cells", ErrInvalidTemplate)
+	}
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	template := Template{Blocks: make([][]bool, len(lines)), Grid: make(Grid, len(lines))}
+	width := len([]rune(strings.TrimSpace(lines[0])))
+	for y, line := range lines {
+		row := []rune(strings.TrimSpace(line))
+		if len(row) != width {
+			return Template{}, fmt.Errorf("%w: row %d has %d cells but the first row has %d", ErrInvalidTemplate, y+1, len(row), width)
+		}
+		template.Blocks[y] = make([]bool, width)
+		template.Grid[y] = make([]Cell, width)
+		for x, char := range row {
+			switch char {
+			case '#':
+				template.Blocks[y][x] = true
+			case '.':
+			default:
+				template.Grid[y][x] = Cell{Char: char}
+			}
+		}
+	}
+	if err := template.validate(); err != nil {
+		return Template{}, err
+	}
+	return template, nil
+}
+
+// validate checks the blocks are a rectangle of at least one cell, every open cell is part of a
+// word and the grid, if given, is the same size with no letters in blocked cells.
+func (t Template) validate() error {
+	if len(t.Blocks) == 0 || len(t.Blocks[0]) == 0 {
+		return fmt.Errorf("%w: template has no cells", ErrInvalidTemplate)
+	}
+	width, height := len(t.Blocks[0]), len(t.Blocks)
+	for y, row := range t.Blocks {
+		if len(row) != width {
+			return fmt.Errorf("%w: row %d has %d cells but the first row has %d", ErrInvalidTemplate, y+1, len(row), width)
+		}
+	}
+	for y, row := range t.Blocks {
+		for x, blocked := range row {
+			if blocked {
+				continue
+			}
+			across, down := runLength(t.Blocks, x, y, 1, 0), runLength(t.Blocks, x, y, 0, 1)
+			if across < minSlotLength && down < minSlotLength {
+				return fmt.Errorf("%w: the open cell in row %d column %d is not part of a word of at least %d cells", ErrInvalidTemplate, y+1, x+1, minSlotLength)
+			}
+			if across == 2 || down == 2 {
+				return fmt.Errorf("%w: the open cell in row %d column %d is part of a word of 2 cells", ErrInvalidTemplate, y+1, x+1)
+			}
+		}
+	}
+	if t.Grid == nil {
+		return nil
+	}
+	if t.Grid.Height() != height {
+		return fmt.Errorf("%w: grid has %d rows but the blocks have %d", ErrInvalidTemplate, t.Grid.Height(), height)
+	}
+	for y, row := range t.Grid {
+		if len(row) != width {
+			return fmt.Errorf("%w: grid row %d has %d cells but the blocks have %d", ErrInvalidTemplate, y+1, len(row), width)
+		}
+		for x, cell := range row {
+			if !cell.Empty() && t.Blocks[y][x] {
+				return fmt.Errorf("%w: the letter in row %d column %d is in a blocked cell", ErrInvalidTemplate, y+1, x+1)
+			}
+		}
+	}
+	return nil
+}
+
+// Autofill fills the open cells of the template with words from the dictionary, preferring higher
+// scoring entries. Each word is used at most once. The words are cleaned up in the same way as
+// Generate so WithAlphabet, WithNormalizer and WithKeepSpecialCharacters apply, as do WithTimeBudget,
+// WithNodeBudget and WithSeed, which breaks ties between entries with the same score.
+//
+// The search fills the slot with the fewest remaining candidates at each step and, after each
+// choice, removes any candidates from the crossing slots that no longer have a matching letter.
+// If no fill was found the crossword is nil and the error is ErrGridNotFilled, or the reason the
+// search stopped if the context was cancelled or the time budget was exceeded. ErrInvalidTemplate
+// is returned if the template is not a rectangle or its Grid is a different size to its Blocks.
+func Autofill(ctx context.Context, template Template, dictionary []DictionaryEntry, opts ...GeneratorOpt) (*Crossword, error) {
+	if err := template.validate(); err != nil {
+		return nil, err
+	}
+	options := resolveGeneratorOptions(opts)
+	if options.timeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, options.timeBudget, ErrTimeBudgetExceeded)
+		defer cancel()
+	}
+
+	entries := slices.DeleteFunc(slices.Clone(dictionary), func(entry DictionaryEntry) bool {
+		return options.minScore != nil && entry.Score < *options.minScore
+	})
+	words := make([]Word, len(entries))
+	for k := range entries {
+		words[k] = entries[k].Word
+	}
+	prepareWords(words, options)
+	for k := range entries {
+		entries[k].Word = words[k]
+	}
+
+	// entries with the same score are tried in a random order
+	options.rand.Shuffle(len(entries), func(i, j int) {
+		entries[i], entries[j] = entries[j], entries[i]
+	})
+	slices.SortStableFunc(entries, func(a, b DictionaryEntry) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	for k := range entries {
+		words[k] = entries[k].Word
+	}
+
+	// letters in the template are cleaned up in the same way as the words so they match
+	if template.Grid != nil {
+		grid := NewRectGrid(template.Grid.Width(), template.Grid.Height())
+		for y := range template.Grid {
+			for x, cell := range template.Grid[y] {
+				if cell.Empty() {
+					continue
+				}
+				letter := []Word{{Word: cell.String()}}
+				prepareWords(letter, options)
+				chars := letter[0].chars()
+				if len(chars) != 1 {
+					return nil, fmt.Errorf("%w: the letter %q in row %d column %d becomes %q", ErrInvalidTemplate, cell.Char, y+1, x+1, letter[0].Word)
+				}
+				grid[y][x] = Cell{Char: chars[0]}
+			}
+		}
+		template.Grid = grid
+	}
+
+	budget := options.nodeBudget
+	if budget <= 0 {
+		budget = defaultFillNodeBudget
+	}
+	f := newFiller(template, words, budget)
+	if f.fill(ctx) {
+		cw := f.crossword()
+		if options.seed != nil {
+			seed := *options.seed
+			cw.Seed = &seed
+		}
+		return cw, nil
+	}
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return nil, ErrGridNotFilled
+}
+
+// slot is a run of open cells in a blocked grid that must be filled with a single word.
+type slot struct {
+	X        int
+	Y        int
+	Vertical bool
+	Length   int
+}
+
+func (s slot) cell(charIdx int) (x, y int) {
+	if s.Vertical {
+		return s.X, s.Y + charIdx
+	}
+	return s.X + charIdx, s.Y
+}
+
+// findSlots returns every horizontal and vertical run of at least minLength open cells in
+// reading order of their first cell, with the across slot before the down slot if both start in
+// the same cell. blocks[y][x] is true for blocked cells.
+func findSlots(blocks [][]bool, minLength int) []slot {
+	var slots []slot
+	height := len(blocks)
+	for y := range height {
+		width := len(blocks[y])
+		for x := range width {
+			if blocks[y][x] {
+				continue
+			}
+			if x == 0 || blocks[y][x-1] {
+				length := 0
+				for x+length < width && !blocks[y][x+length] {
+					length++
+				}
+				if length >= minLength {
+					slots = append(slots, slot{X: x, Y: y, Length: length})
+				}
+			}
+			if y == 0 || blocks[y-1][x] {
+				length := 0
+				for y+length < height && !blocks[y+length][x] {
+					length++
+				}
+				if length >= minLength {
+					slots = append(slots, slot{X: x, Y: y, Vertical: true, Length: length})
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// slotNumbers numbers the slots in the conventional way, where each cell starting a slot is
+// numbered in reading order so across and down slots starting in the same cell share a number.
+// The slots must be in the order returned by findSlots.
+func slotNumbers(slots []slot) []int {
+	numbers := make([]int, len(slots))
+	number := 0
+	for s, sl := range slots {
+		if s == 0 || slots[s-1].X != sl.X || slots[s-1].Y != sl.Y {
+			number++
+		}
+		numbers[s] = number
+	}
+	return numbers
+}
+
+// crossing identifies the character of another slot sharing a cell. slot is -1 if the cell is
+// not part of another slot.
+type crossing struct {
+	slot    int
+	charIdx int
+}
+
+// filler assigns words to the slots of a blocked grid by backtracking. The candidates for each slot
+// are narrowed after every choice so a branch is abandoned as soon as any slot has none left.
+type filler struct {
+	width     int
+	height    int
+	slots     []slot
+	crossings [][]crossing
+	words     []Word
+	chars     [][]rune
+
+	// candidates holds the index of each word still possible in each slot. The slices are replaced
+	// rather than modified so they can be restored when backtracking.
+	candidates [][]int
+	assigned   []bool
+	failed     bool
+
+	nodes  int
+	budget int
+}
+
+// newFiller prepares to fill the template with the words, which are tried in the order given.
+func newFiller(template Template, words []Word, budget int) *filler {
+	f := &filler{
+		height: len(template.Blocks),
+		slots:  findSlots(template.Blocks, minSlotLength),
+		budget: budget,
+	}
+	if f.height > 0 {
+		f.width = len(template.Blocks[0])
+	}
+	f.candidates = make([][]int, len(f.slots))
+	f.assigned = make([]bool, len(f.slots))
+
+	// map each cell back to the slots using it to find where they cross
+	cellSlots := map[[2]int][]crossing{}
+	for s, sl := range f.slots {
+		for charIdx := range sl.Length {
+			x, y := sl.cell(charIdx)
+			cellSlots[[2]int{x, y}] = append(cellSlots[[2]int{x, y}], crossing{slot: s, charIdx: charIdx})
+		}
+	}
+	f.crossings = make([][]crossing, len(f.slots))
+	for s, sl := range f.slots {
+		f.crossings[s] = make([]crossing, sl.Length)
+		for charIdx := range sl.Length {
+			x, y := sl.cell(charIdx)
+			f.crossings[s][charIdx] = crossing{slot: -1}
+			for _, c := range cellSlots[[2]int{x, y}] {
+				if c.slot != s {
+					f.crossings[s][charIdx] = c
+				}
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, w := range words {
+		if seen[w.Word] || w.Len() < minSlotLength {
+			continue
+		}
+		seen[w.Word] = true
+		f.addWord(w)
+	}
+
+	queue := make([]int, 0, len(f.slots))
+	for s, sl := range f.slots {
+		fixed := make([]rune, sl.Length)
+		complete := true
+		for charIdx := range sl.Length {
+			x, y := sl.cell(charIdx)
+			if template.Grid != nil {
+				fixed[charIdx] = template.Grid[y][x].Char
+			}
+			if fixed[charIdx] == 0 {
+				complete = false
+			}
+		}
+		for w, chars := range f.chars {
+			if matchesFixed(chars, fixed) {
+				f.candidates[s] = append(f.candidates[s], w)
+			}
+		}
+		if complete && len(f.candidates[s]) == 0 {
+			// words already completed in the grid are kept even if they are not in the dictionary
+			f.candidates[s] = []int{f.addWord(Word{Word: string(fixed)})}
+		}
+		if len(f.candidates[s]) == 0 {
+			f.failed = true
+		}
+		queue = append(queue, s)
+	}
+	if !f.failed {
+		f.failed = !f.propagate(queue)
+	}
+	return f
+}
+
+func (f *filler) addWord(w Word) int {
+	f.words = append(f.words, w)
+	f.chars = append(f.chars, w.chars())
+	return len(f.words) - 1
+}
+
+// matchesFixed checks the word has the same length and letters as the fixed characters, where
+// zero matches any letter.
+func matchesFixed(chars []rune, fixed []rune) bool {
+	if len(chars) != len(fixed) {
+		return false
+	}
+	for charIdx, char := range fixed {
+		if char != 0 && chars[charIdx] != char {
+			return false
+		}
+	}
+	return true
+}
+
+// fill attempts to fill every slot. It returns false if no fill was found before the node budget
+// was spent or the context was cancelled.
+func (f *filler) fill(ctx context.Context) bool {
+	if f.failed || ctx.Err() != nil || f.nodes >= f.budget {
+		return false
+	}
+	f.nodes++
+
+	next := -1
+	for s := range f.slots {
+		if f.assigned[s] {
+			continue
+		}
+		if next == -1 || len(f.candidates[s]) < len(f.candidates[next]) {
+			next = s
+		}
+	}
+	if next == -1 {
+		return true
+	}
+
+	for _, w := range f.candidates[next] {
+		saved := slices.Clone(f.candidates)
+		if f.assign(next, w) && f.fill(ctx) {
+			return true
+		}
+		f.candidates = saved
+		f.assigned[next] = false
+	}
+	return false
+}
+
+// assign chooses the word for the slot and removes it from the other slots before propagating
+// the change to the crossing slots. It returns false if any slot is left without candidates.
+func (f *filler) assign(s int, w int) bool {
+	f.assigned[s] = true
+	f.candidates[s] = []int{w}
+	queue := []int{s}
+	for other := range f.slots {
+		if other == s || f.assigned[other] || f.slots[other].Length != f.slots[s].Length {
+			continue
+		}
+		if remaining := without(f.candidates[other], w); len(remaining) < len(f.candidates[other]) {
+			if len(remaining) == 0 {
+				return false
+			}
+			f.candidates[other] = remaining
+			queue = append(queue, other)
+		}
+	}
+	return f.propagate(queue)
+}
+
+// propagate narrows the candidates of the slots crossing each slot in the queue to those with a
+// letter that one of its candidates could also have. Slots that change are queued in turn.
+func (f *filler) propagate(queue []int) bool {
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for charIdx, c := range f.crossings[s] {
+			if c.slot == -1 {
+				continue
+			}
+			allowed := map[rune]bool{}
+			for _, w := range f.candidates[s] {
+				allowed[f.chars[w][charIdx]] = true
+			}
+			var remaining []int
+			for _, w := range f.candidates[c.slot] {
+				if allowed[f.chars[w][c.charIdx]] {
+					remaining = append(remaining, w)
+				}
+			}
+			if len(remaining) == 0 {
+				return false
+			}
+			if len(remaining) < len(f.candidates[c.slot]) {
+				f.candidates[c.slot] = remaining
+				queue = append(queue, c.slot)
+			}
+		}
+	}
+	return true
+}
+
+func without(candidates []int, w int) []int {
+	remaining := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != w {
+			remaining = append(remaining, candidate)
+		}
+	}
+	return remaining
+}
+
+// crossword builds a crossword from the filled slots using the conventional numbering where
+// each cell starting an across or down word is numbered in reading order.
+func (f *filler) crossword() *Crossword {
+	cw := &Crossword{Grid: NewRectGrid(f.width, f.height), Report: &GenerationReport{}}
+
+	numbers := slotNumbers(f.slots)
+	for s, sl := range f.slots {
+		word := f.words[f.candidates[s][0]]
+		for charIdx, char := range word.chars() {
+			x, y := sl.cell(charIdx)
+			cw.Grid[y][x] = Cell{Char: char, CharIdx: charIdx}
+		}
+		cw.Words = append(cw.Words, Placement{
+			ID:       numbers[s],
+			Word:     word,
+			X:        sl.X,
+			Y:        sl.Y,
+			Vertical: sl.Vertical,
+		})
+	}
+	return cw
+}
@@ -0,0 +1,128 @@
+package crossword
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutofill(t *testing.T) {
+	dictionary := []DictionaryEntry{
+		{Word: Word{Word: "cat"}, Score: 10},
+		{Word: Word{Word: "dog"}, Score: 90},
+		{Word: Word{Word: "thé"}, Score: 5},
+	}
+	tests := []struct {
+		name     string
+		template string
+		opts     []GeneratorOpt
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "highest score is preferred",
+			template: "#####\n#...#\n#####",
+			want:     "#####\n#DOG#\n#####\n",
+		},
+		{
+			name:     "letters in the template are kept",
+			template: "#####\n#..t#\n#####",
+			want:     "#####\n#CAT#\n#####\n",
+		},
+		{
+			name:     "low scores are excluded",
+			template: "#####\n#...#\n#####",
+			opts:     []GeneratorOpt{WithMinScore(95)},
+			wantErr:  ErrGridNotFilled,
+		},
+		{
+			name:     "letters in the template are normalized",
+			template: "#####\n#..é#\n#####",
+			opts:     []GeneratorOpt{WithNormalizer(NormalizerFold)},
+			want:     "#####\n#THE#\n#####\n",
+		},
+		{
+			name:     "letters that normalize to more than one character",
+			template: "#####\n#..ß#\n#####",
+			opts:     []GeneratorOpt{WithNormalizer(NormalizerGerman)},
+			wantErr:  ErrInvalidTemplate,
+		},
+		{
+			name:     "letters outside the alphabet",
+			template: "#####\n#..!#\n#####",
+			opts:     []GeneratorOpt{WithAlphabet(AlphabetLatin)},
+			wantErr:  ErrInvalidTemplate,
+		},
+		{
+			name:     "words are not repeated",
+			template: "###\n...\n###\n...\n###",
+			want:     "###\nDOG\n###\nCAT\n###\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseTemplate(tt.template)
+			require.NoError(t, err)
+			cw, err := Autofill(context.Background(), template, dictionary, tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, RenderText(cw, WithAllSolved(true)))
+		})
+	}
+}
+
+func TestAutofill_crossingWords(t *testing.T) {
+	var dictionary []DictionaryEntry
+	for _, w := range []string{"bat", "ore", "wed", "bow", "are", "ted", "bot", "tow"} {
+		dictionary = append(dictionary, DictionaryEntry{Word: Word{Word: w, Clue: w + " clue"}})
+	}
+	template, err := ParseTemplate("...\n...\nW..")
+	require.NoError(t, err)
+
+	cw, err := Autofill(context.Background(), template, dictionary, WithSeed(1))
+	require.NoError(t, err)
+	require.Equal(t, "BAT\nORE\nWED\n", RenderText(cw, WithAllSolved(true)))
+	require.Equal(t, "bat clue", cw.Words[0].Word.Clue)
+	require.Equal(t, "A1", cw.Words[0].ClueID())
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Autofill(ctx, template, dictionary)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("invalid template", func(t *testing.T) {
+		for _, template := range []Template{
+			{},
+			{Blocks: [][]bool{{false, false, false}, {false}}},
+			{Blocks: [][]bool{{false, false, false}}, Grid: NewRectGrid(1, 1)},
+			{Blocks: [][]bool{{false, false, false}}, Grid: NewRectGrid(3, 2)},
+			{Blocks: [][]bool{{true, false, true}, {false, false, false}}},
+			{Blocks: [][]bool{{false, false, true}}, Grid: Grid{{{}, {}, {Char: 'A'}}}},
+		} {
+			_, err := Autofill(context.Background(), template, dictionary)
+			require.ErrorIs(t, err, ErrInvalidTemplate)
+		}
+	})
+}
+
+func TestParseTemplate(t *testing.T) {
+	template, err := ParseTemplate("#...\n..a.\n#...")
+	require.NoError(t, err)
+	require.Equal(t, [][]bool{{true, false, false, false}, {false, false, false, false}, {true, false, false, false}}, template.Blocks)
+	require.Equal(t, 'a', template.Grid[1][2].Char)
+
+	_, err = ParseTemplate("#.a\n..")
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = ParseTemplate(" \n")
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+
+	// the top cells are not part of any word and would be lost
+	_, err = ParseTemplate(".#.\n...")
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+}
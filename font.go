@@ -0,0 +1,159 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// FontSource is a font given to WithFont, WithLabelFont or WithClueFont: either a parsed TrueType font
+// or the contents of a TrueType (.ttf) or OpenType (.otf) font file.
+type FontSource interface {
+	*truetype.Font | []byte
+}
+
+// WithFont sets the font of all the text i.e. the letters, labels and clues. WithLabelFont and
+// WithClueFont take precedence over it. The default is the Go regular font. Fonts given as bytes are
+// embedded by RenderSVG and RenderHTML, which otherwise refer to the font by name. RenderPDF can only
+// use fonts given as bytes.
+func WithFont[F FontSource](f F) RenderOption {
+	return func(opts *renderOpts) {
+		opts.font = opts.parseFont(f)
+	}
+}
+
+// WithLabelFont sets the font of the labels that identify the words in the grid. See WithFont.
+func WithLabelFont[F FontSource](f F) RenderOption {
+	return func(opts *renderOpts) {
+		opts.labelFont = opts.parseFont(f)
+	}
+}
+
+// WithClueFont sets the font of the clues and titles. See WithFont.
+func WithClueFont[F FontSource](f F) RenderOption {
+	return func(opts *renderOpts) {
+		opts.clueFont = opts.parseFont(f)
+	}
+}
+
+// defaultFont is parsed the first time a renderer needs it.
+var defaultFont = sync.OnceValues(func() (*renderFont, error) {
+	ttf, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default font: %w", err)
+	}
+	return &renderFont{data: goregular.TTF, ttf: ttf, builtin: true}, nil
+})
+
+// renderFont is a font parsed by either the truetype or opentype package.
+type renderFont struct {
+	// data is the font file, which is nil if the font was given as a *truetype.Font.
+	data    []byte
+	ttf     *truetype.Font
+	otf     *opentype.Font
+	builtin bool
+}
+
+// parseFont parses a FontSource, recording the first error so it can be returned by the renderer.
+func (opts *renderOpts) parseFont(f any) *renderFont {
+	switch f := f.(type) {
+	case *truetype.Font:
+		if f == nil {
+			return nil
+		}
+		return &renderFont{ttf: f}
+	case []byte:
+		otf, err := opentype.Parse(f)
+		if err != nil {
+			if opts.err == nil {
+				opts.err = fmt.Errorf("failed to parse font: %w", err)
+			}
+			return nil
+		}
+		return &renderFont{data: f, otf: otf}
+	}
+	return nil
+}
+
+// loadFonts sets the fonts which were not given to the default and returns any error parsing them.
+func (opts *renderOpts) loadFonts() error {
+	if opts.err != nil {
+		return opts.err
+	}
+	if opts.font == nil {
+		var err error
+		if opts.font, err = defaultFont(); err != nil {
+			return err
+		}
+	}
+	if opts.labelFont == nil {
+		opts.labelFont = opts.font
+	}
+	if opts.clueFont == nil {
+		opts.clueFont = opts.font
+	}
+	return nil
+}
+
+// face creates a face of the given size in points at 72 DPI i.e. the size is in pixels.
+func (f *renderFont) face(size float64) font.Face {
+	if f.ttf != nil {
+		return truetype.NewFace(f.ttf, &truetype.Options{Size: size})
+	}
+	// an error is only returned for invalid options
+	face, _ := opentype.NewFace(f.otf, &opentype.FaceOptions{Size: size, DPI: 72})
+	return face
+}
+
+// family is the name of the font e.g. "Go".
+func (f *renderFont) family() string {
+	if f.ttf != nil {
+		return f.ttf.Name(truetype.NameIDFontFamily)
+	}
+	name, _ := f.otf.Name(nil, sfnt.NameIDFamily)
+	return name
+}
+
+// cssFonts returns the CSS font families of the letters, labels and clues along with @font-face rules
+// embedding the fonts which were given as bytes. Other fonts are referred to by name.
+func (opts *renderOpts) cssFonts() (letters, labels, clues, fontFaces string) {
+	names := map[*renderFont]string{}
+	rules := &strings.Builder{}
+	family := func(f *renderFont) string {
+		if name, ok := names[f]; ok {
+			return name
+		}
+		name := fmt.Sprintf("'%s', sans-serif", cssFontName(f.family()))
+		if f.data != nil && !f.builtin {
+			embedded := fmt.Sprintf("crossword-font-%d", len(names)+1)
+			format, mime := "truetype", "font/ttf"
+			if bytes.HasPrefix(f.data, []byte("OTTO")) {
+				format, mime = "opentype", "font/otf"
+			}
+			fmt.Fprintf(rules, "@font-face { font-family: '%s'; src: url(data:%s;base64,%s) format('%s'); }\n", embedded, mime, base64.StdEncoding.EncodeToString(f.data), format)
+			name = fmt.Sprintf("'%s', %s", embedded, name)
+		}
+		names[f] = name
+		return name
+	}
+	return family(opts.font), family(opts.labelFont), family(opts.clueFont), rules.String()
+}
+
+// cssFontName removes the characters from a font name which would need to be escaped in CSS.
+func cssFontName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+}
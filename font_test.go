@@ -0,0 +1,49 @@
+package crossword
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+func TestWithFont(t *testing.T) {
+	mono, err := truetype.Parse(gomono.TTF)
+	require.NoError(t, err)
+
+	t.Run("fonts given as bytes", func(t *testing.T) {
+		opts := []RenderOption{WithClues(true), WithFont(gobold.TTF), WithLabelFont(gomono.TTF)}
+
+		dc, err := RenderPNG(testCrossword(t), 600, 400, opts...)
+		require.NoError(t, err)
+		require.NotNil(t, dc)
+
+		svg := &bytes.Buffer{}
+		require.NoError(t, RenderSVG(svg, testCrossword(t), 600, 400, opts...))
+		require.Equal(t, 2, bytes.Count(svg.Bytes(), []byte("@font-face")))
+		require.Contains(t, svg.String(), `<g id="grid" font-family="&#39;crossword-font-1&#39;, &#39;Go&#39;, sans-serif">`)
+		require.Contains(t, svg.String(), `font-family="&#39;crossword-font-2&#39;, &#39;Go Mono&#39;, sans-serif"`)
+		require.Contains(t, svg.String(), `<g id="clues" font-family="&#39;crossword-font-1&#39;`)
+
+		page := &bytes.Buffer{}
+		require.NoError(t, RenderHTML(page, testCrossword(t), opts...))
+		require.Equal(t, 2, bytes.Count(page.Bytes(), []byte("@font-face")))
+
+		require.NoError(t, RenderPDF(&bytes.Buffer{}, testCrossword(t), opts...))
+	})
+	t.Run("parsed font", func(t *testing.T) {
+		svg := &bytes.Buffer{}
+		require.NoError(t, RenderSVG(svg, testCrossword(t), 600, 400, WithClueFont(mono), WithClues(true)))
+		require.NotContains(t, svg.String(), "@font-face")
+		require.Contains(t, svg.String(), `<g id="clues" font-family="&#39;Go Mono&#39;, sans-serif"`)
+
+		require.Error(t, RenderPDF(&bytes.Buffer{}, testCrossword(t), WithClueFont(mono)))
+	})
+	t.Run("invalid font", func(t *testing.T) {
+		_, err := RenderPNG(testCrossword(t), 600, 400, WithFont([]byte("not a font")))
+		require.ErrorContains(t, err, "failed to parse font")
+	})
+}
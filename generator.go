@@ -2,14 +2,37 @@ package crossword
 
 import (
 	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"math/rand/v2"
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+// ErrTimeBudgetExceeded is the reason returned by GenerateContext when the duration given
+// to WithTimeBudget elapses before generation completes.
+var ErrTimeBudgetExceeded = errors.New("generation time budget exceeded")
+
+// errAllWordsPlaced is used internally to stop remaining attempts once a result contains every word.
+var errAllWordsPlaced = errors.New("all words placed")
+
+// ErrRequiredWordsNotPlaced is returned by GenerateContext when a word marked as Required
+// is missing from the best crossword. The crossword is still returned.
+var ErrRequiredWordsNotPlaced = errors.New("required words could not be placed")
+
+// ErrInvalidPinnedPlacement is returned by GenerateContext when a placement given to WithPinned
+// is outside the grid or collides with another pinned placement.
+var ErrInvalidPinnedPlacement = errors.New("invalid pinned placement")
+
 var spaces = regexp.MustCompile(`\s+`)
-var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9\s]+`)
 
 type GeneratorOpt func(opts *generatorOpts)
 
@@ -33,53 +56,363 @@ func WithAllAttempts(allAttempts bool) GeneratorOpt {
 	}
 }
 
+// WithTimeBudget limits how long generation may run. When the budget is exceeded the best
+// crossword found so far is returned along with ErrTimeBudgetExceeded. Zero means no limit.
+func WithTimeBudget(budget time.Duration) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.timeBudget = budget
+	}
+}
+
+// WithSeed seeds the random source used to shuffle words between attempts so the same
+// seed and word list will always generate the same crossword.
+func WithSeed(seed uint64) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.seed = &seed
+	}
+}
+
+// WithRand sets the random source used to shuffle words between attempts. Since the seed
+// cannot be known it will not be recorded on the generated crossword.
+func WithRand(r *rand.Rand) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.rand = r
+	}
+}
+
+// WithConcurrency runs attempts on a pool of n workers, each with its own grid. Results are
+// merged in attempt order using the same rules as sequential generation so seeded output
+// does not depend on scheduling when WithAllAttempts is used.
+func WithConcurrency(n int) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.concurrency = n
+	}
+}
+
+// WithCrop removes any empty rows and columns from the generated crossword. See Crossword.Crop.
+func WithCrop(crop bool) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.crop = crop
+	}
+}
+
+// WithCentre moves the generated words to the middle of the grid. See Crossword.Centre.
+func WithCentre(centre bool) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.centre = centre
+	}
+}
+
+// WithScorer sets the strategy used to choose between valid placements for each word.
+// The default is IntersectionScorer.
+func WithScorer(scorer Scorer) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.scorer = scorer
+	}
+}
+
+// WithPinned fixes placements in the grid before generation begins. They will be in every
+// generated layout and the remaining words will be attached to them. Pinned words are cleaned up
+// in the same way as the generated words, and are validated using the same collision rules.
+func WithPinned(placements ...Placement) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.pinned = append(opts.pinned, placements...)
+	}
+}
+
+// WithAlphabet sets the characters allowed in words and how they are upper-cased.
+// The default is AlphabetUnicode.
+func WithAlphabet(alphabet Alphabet) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.alphabet = alphabet
+	}
+}
+
+// WithNormalizer converts each word before it is placed e.g. to fold accented letters. The word as
+// given is kept in Word.Original. Only one normalizer is used, so if the option is given more than
+// once the last one applies. See NormalizerFold and the language specific normalizers.
+func WithNormalizer(normalizer Normalizer) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.normalizer = normalizer
+	}
+}
+
 func resolveGeneratorOptions(opts []GeneratorOpt) *generatorOpts {
-	resolved := &generatorOpts{}
+	resolved := &generatorOpts{scorer: IntersectionScorer{}, alphabet: AlphabetUnicode}
 	for _, o := range opts {
 		o(resolved)
 	}
+	if resolved.rand == nil {
+		if resolved.seed == nil {
+			seed := rand.Uint64()
+			resolved.seed = &seed
+		}
+		resolved.rand = newSeededRand(*resolved.seed)
+	}
 	return resolved
 }
 
+func newSeededRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, 0))
+}
+
 type generatorOpts struct {
 	revealFirstChars      bool
 	keepSpecialCharacters bool
 	runAllAttempts        bool
+	timeBudget            time.Duration
+	seed                  *uint64
+	rand                  *rand.Rand
+	concurrency           int
+	crop                  bool
+	centre                bool
+	scorer                Scorer
+	pinned                []Placement
+	nodeBudget            int
+	branching             int
+	alphabet              Alphabet
+	normalizer            Normalizer
+	blockRatio            *float64
+	maxWordLength         int
+	minScore              *int
+	mask                  Mask
 }
 
 func Generate(gridSize int, words []Word, attempts int, opts ...GeneratorOpt) *Crossword {
 	return NewGenerator(gridSize).Generate(words, attempts, opts...)
 }
 
+// GenerateContext is the same as Generate but stops early if the context is cancelled.
+// See Generator.GenerateContext.
+func GenerateContext(ctx context.Context, gridSize int, words []Word, attempts int, opts ...GeneratorOpt) (*Crossword, error) {
+	return NewGenerator(gridSize).GenerateContext(ctx, words, attempts, opts...)
+}
+
 func NewGenerator(gridSize int) *Generator {
-	return &Generator{gridSize: gridSize, grid: NewGrid(gridSize)}
+	return NewRectGenerator(gridSize, gridSize)
 }
 
+// NewRectGenerator creates a generator for a grid with the given number of columns (width) and rows (height).
+func NewRectGenerator(width, height int) *Generator {
+	return &Generator{width: width, height: height, grid: NewRectGrid(width, height)}
+}
+
+// LayoutGenerator is implemented by each of the strategies for generating a crossword layout
+// from a list of words, so they can be used interchangeably.
+type LayoutGenerator interface {
+	Generate(words []Word, attempts int, opts ...GeneratorOpt) *Crossword
+	GenerateContext(ctx context.Context, words []Word, attempts int, opts ...GeneratorOpt) (*Crossword, error)
+}
+
+var _ LayoutGenerator = &Generator{}
+
+// Generator places each word in turn at the best scoring position available at the time,
+// never revisiting a choice once made.
 type Generator struct {
-	gridSize    int
+	width       int
+	height      int
 	grid        Grid
+	mask        Mask
 	placedWords []Placement
 	totalScore  int
+	unplaced    []UnplacedWord
 }
 
 func (g *Generator) Generate(words []Word, attempts int, opts ...GeneratorOpt) *Crossword {
+	cw, _ := g.GenerateContext(context.Background(), words, attempts, opts...)
+	return cw
+}
+
+// GenerateContext generates a crossword, checking the context between each start-word pass.
+// If the context is cancelled or the time budget is exceeded the best crossword found so far is
+// returned along with the reason generation stopped (e.g. context.Canceled or ErrTimeBudgetExceeded).
+// The crossword is nil if the context was already cancelled, otherwise the first pass always
+// completes so there is a crossword whenever there are words to place.
+// A nil error means that generation ran to completion.
+func (g *Generator) GenerateContext(ctx context.Context, words []Word, attempts int, opts ...GeneratorOpt) (*Crossword, error) {
+	return generate(ctx, g.width, g.height, words, attempts, resolveGeneratorOptions(opts), func(worker int) attemptRunner {
+		if worker == 0 {
+			return g
+		}
+		return NewRectGenerator(g.width, g.height)
+	})
+}
+
+// attemptRunner is implemented by each generation strategy to produce a layout from a single
+// ordering of the words.
+type attemptRunner interface {
+	// runAttempt returns the best layout found for the words. If the context is cancelled before
+	// the attempt completes the result will be partial and stopped will be true. The first pass
+	// should complete regardless so that an attempt which has started always has a result.
+	runAttempt(ctx context.Context, words []Word, options *generatorOpts) (best *Crossword, stopped bool)
+}
+
+// generate prepares the words and runs the attempts on a pool of workers created by newWorker,
+// returning the best result.
+func generate(
+	ctx context.Context,
+	width, height int,
+	words []Word,
+	attempts int,
+	options *generatorOpts,
+	newWorker func(worker int) attemptRunner,
+) (*Crossword, error) {
+
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if options.timeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, options.timeBudget, ErrTimeBudgetExceeded)
+		defer cancel()
+	}
+
+	prepareWords(words, options)
+
+	// pinned placements are validated up-front since they will be in every generated layout
+	options.pinned = slices.Clone(options.pinned)
+	for k := range options.pinned {
+		pinnedWord := []Word{options.pinned[k].Word}
+		prepareWords(pinnedWord, options)
+		options.pinned[k].Word = pinnedWord[0]
+	}
+	pins := NewRectGenerator(width, height)
+	pins.mask = options.mask
+	if err := pins.validatePinned(options.pinned); err != nil {
+		return nil, err
+	}
+	if len(options.pinned) > 0 {
+		// words that are already pinned do not need to be placed
+		words = slices.DeleteFunc(slices.Clone(words), func(w Word) bool {
+			return slices.ContainsFunc(options.pinned, func(pl Placement) bool {
+				return pl.Word.Word == w.Word
+			})
+		})
+	}
 
-	options := resolveGeneratorOptions(opts)
+	// retain the original order for reporting since words will be re-ordered by each attempt.
+	inputWords := slices.Clone(words)
+
+	workCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// a negative number of attempts is the same as none, so no crossword is generated
+	attempts = max(0, attempts)
+
+	// each attempt's result is stored by index so they can be merged in order once all
+	// workers have finished. This keeps the outcome independent of scheduling.
+	results := make([]*Crossword, attempts)
+	var interrupted atomic.Bool
+
+	jobs := make(chan attemptJob)
+	wg := sync.WaitGroup{}
+	for w := range max(1, options.concurrency) {
+		worker := newWorker(w)
+		wg.Go(func() {
+			for job := range jobs {
+				cw, stopped := worker.runAttempt(workCtx, job.words, options)
+				if stopped {
+					interrupted.Store(true)
+				}
+				results[job.attempt] = cw
+				if !options.runAllAttempts && cw != nil && len(cw.Report.Unplaced) == 0 {
+					cancel(errAllWordsPlaced)
+				}
+			}
+		})
+	}
+
+dispatch:
+	for k := range attempts {
+		if k == 0 {
+			// first attempt sort words by length
+			slices.SortStableFunc(words, func(a, b Word) int {
+				if a.Len() == b.Len() {
+					return cmp.Compare(a.Word, b.Word)
+				}
+				if a.Len() > b.Len() {
+					return -1
+				}
+				return 1
+			})
+		} else {
+			// remaining attempts should randomize the words instead
+			options.rand.Shuffle(len(words), func(i, j int) {
+				words[i], words[j] = words[j], words[i]
+			})
+		}
+		job := attemptJob{attempt: k, words: slices.Clone(words)}
+		if k == 0 {
+			// the first attempt is always run so there is a result even if the context is
+			// cancelled before a worker picks it up
+			jobs <- job
+			continue
+		}
+		select {
+		case jobs <- job:
+		case <-workCtx.Done():
+			interrupted.Store(true)
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var bestCrossword *Crossword
+	for _, cw := range results {
+		if cw != nil && (bestCrossword == nil || cw.betterThan(bestCrossword)) {
+			bestCrossword = cw
+		}
+	}
+	if bestCrossword != nil {
+		if options.seed != nil {
+			seed := *options.seed
+			bestCrossword.Seed = &seed
+		}
+		bestCrossword.Report.sortUnplaced(inputWords)
+		if options.crop {
+			bestCrossword.Crop()
+		}
+		if options.centre {
+			bestCrossword.Centre()
+		}
+	}
+	if interrupted.Load() && !errors.Is(context.Cause(workCtx), errAllWordsPlaced) {
+		return bestCrossword, context.Cause(ctx)
+	}
+	if err := checkRequiredWords(bestCrossword, inputWords); err != nil {
+		return bestCrossword, err
+	}
+	return bestCrossword, nil
+}
 
+// prepareWords strips unwanted characters from the words and applies any word related options.
+func prepareWords(words []Word, options *generatorOpts) {
 	// strip unnecessary characters
 	for k := range words {
+		// combining characters are composed where possible so they occupy a single cell
+		words[k].Word = norm.NFC.String(words[k].Word)
+		if options.normalizer != nil {
+			if normalized := options.normalizer.Normalize(words[k].Word); normalized != words[k].Word {
+				words[k].Original = words[k].Word
+				words[k].Word = normalized
+			}
+		}
 		if !options.keepSpecialCharacters {
-			words[k].Word = nonAlphanumeric.ReplaceAllString(words[k].Word, "")
+			words[k].Word = options.alphabet.strip(words[k].Word)
+			words[k].Original = AlphabetUnicode.strip(words[k].Original)
 		}
 		words[k].Word = strings.TrimSpace(spaces.ReplaceAllString(words[k].Word, " "))
+		words[k].Original = strings.TrimSpace(spaces.ReplaceAllString(words[k].Original, " "))
 	}
 
 	// apply options
 	if options.revealFirstChars {
 		for k := range words {
 			numHints := 1
-			for charIdx, char := range words[k].Word {
-				if charIdx == 0 || (char == ' ' && charIdx+1 < len(words[k].Word) && words[k].Word[charIdx+1] != ' ') {
+			chars := words[k].chars()
+			for charIdx, char := range chars {
+				if charIdx == 0 || (char == ' ' && charIdx+1 < len(chars) && chars[charIdx+1] != ' ') {
 					// note that because the spaces will be removed the stored index not incremented
 					words[k].CharacterHints = append(words[k].CharacterHints, (charIdx+1)-numHints)
 					if charIdx > 0 {
@@ -93,97 +426,197 @@ func (g *Generator) Generate(words []Word, attempts int, opts ...GeneratorOpt) *
 	// cleanup words
 	for k := range words {
 		words[k].LettersCounts = countLetters(words[k].Word)
-		words[k].Word = strings.ReplaceAll(strings.ToUpper(words[k].Word), " ", "")
+		words[k].Word = strings.ReplaceAll(options.alphabet.toUpper(words[k].Word), " ", "")
+		words[k].Original = strings.ReplaceAll(options.alphabet.toUpper(words[k].Original), " ", "")
 	}
+}
 
-	var bestCrossword *Crossword
-	for k := range attempts {
-		if k == 0 {
-			// first attempt sort words by length
-			slices.SortStableFunc(words, func(a, b Word) int {
-				if len(a.Word) == len(b.Word) {
-					return cmp.Compare(a.Word, b.Word)
-				}
-				if len(a.Word) > len(b.Word) {
-					return -1
-				}
-				return 1
-			})
+type attemptJob struct {
+	attempt int
+	words   []Word
+}
+
+// runAttempt runs a pass starting from each of the words and returns the best result.
+func (g *Generator) runAttempt(ctx context.Context, words []Word, options *generatorOpts) (best *Crossword, stopped bool) {
+	passes := len(words)
+	if passes == 0 && len(options.pinned) > 0 {
+		// there is nothing to add to the pinned words but they still make a crossword
+		passes = 1
+	}
+	for startWord := range passes {
+		// the first pass always completes so there is a result
+		if startWord > 0 && ctx.Err() != nil {
+			return best, true
+		}
+		g.runPass(words, startWord, options)
+		if cw := g.crossword(); best == nil || cw.betterThan(best) {
+			best = cw
+		}
+		g.reset()
+	}
+	return best, false
+}
+
+func (g *Generator) runPass(words []Word, startWord int, options *generatorOpts) {
+	g.mask = options.mask
+	for _, pin := range options.pinned {
+		g.placeWord(pin)
+	}
+
+	order := make([]int, 0, len(words))
+	if len(options.pinned) > 0 {
+		// the grid is already seeded so the start word only needs to be the first attached to it
+		if startWord < len(words) {
+			order = append(order, startWord)
+		}
+	} else {
+		// place the first word
+		first := g.firstPlacement(words[startWord])
+		if g.fits(first) {
+			g.placeWord(first)
 		} else {
-			// remaining attempts should randomize the words instead
-			slices.SortFunc(words, func(a, b Word) int {
-				if rand.Float64() > 0.5 {
-					return -1
-				}
-				return 1
-			})
+			g.unplaced = append(g.unplaced, UnplacedWord{Word: first.Word, Reason: UnplacedOverflow})
 		}
-		for startWord := range len(words) {
-			// place the first word
-			g.placeWord(Placement{
-				ID:       1,
-				Word:     words[startWord],
-				X:        0,
-				Y:        0,
-				Vertical: false,
-			})
-			for k, word := range words {
-				if k == startWord {
-					continue
-				}
-				if slices.IndexFunc(g.placedWords, func(placement Placement) bool {
-					return word.Word == placement.Word.Word
-				}) > -1 {
-					continue
-				}
-				placements := g.suggestPlacements(word)
-				if placements == nil {
-					continue
-				}
-				var bestPlacement *Placement
-				var bestScore int
-				for k, pl := range placements {
-					if score := g.scorePlacement(pl); bestPlacement == nil || score > bestScore {
-						bestPlacement = &placements[k]
-						bestScore = score
-					}
-				}
-				if bestPlacement == nil || bestScore < 2 {
+	}
+	for k := range words {
+		if k != startWord {
+			order = append(order, k)
+		}
+	}
+
+	// required words are considered first so they have the most space available. Any that
+	// still cannot be placed get another chance once the rest of the words are in the grid.
+	var retry []Word
+	for _, required := range []bool{true, false} {
+		for _, k := range order {
+			word := words[k]
+			if word.Required != required {
+				continue
+			}
+			if reason := g.placeBest(word, options); reason != 0 {
+				if word.Required && reason != UnplacedDuplicate {
+					retry = append(retry, word)
 					continue
 				}
-
-				g.placeWord(*bestPlacement)
-				g.totalScore += bestScore
+				g.unplaced = append(g.unplaced, UnplacedWord{Word: word, Reason: reason})
 			}
+		}
+	}
+	for _, word := range retry {
+		if reason := g.placeBest(word, options); reason != 0 {
+			g.unplaced = append(g.unplaced, UnplacedWord{Word: word, Reason: reason})
+		}
+	}
+}
 
-			var wordDelta, scoreDelta int
-			if bestCrossword != nil {
-				wordDelta = len(g.placedWords) - len(bestCrossword.Words)
-				scoreDelta = g.totalScore - bestCrossword.TotalScore
-			}
-			if bestCrossword == nil || wordDelta > 0 || (wordDelta == 0 && scoreDelta > 0) {
-				bestCrossword = &Crossword{Words: g.placedWords, Grid: g.grid, TotalScore: g.totalScore}
-			}
-			*g = *NewGenerator(g.gridSize)
+// placeBest places the word at its highest scoring placement. If the word cannot be placed
+// the reason is returned instead.
+func (g *Generator) placeBest(word Word, options *generatorOpts) UnplacedReason {
+	if g.hasWord(word) {
+		return UnplacedDuplicate
+	}
+	placements := g.suggestPlacements(word)
+	if placements == nil {
+		return g.noPlacementReason(word)
+	}
+	candidates := g.candidates(placements, options.scorer)
+	if len(candidates) == 0 {
+		return UnplacedCollision
+	}
+
+	g.placeWord(candidates[0].Placement)
+	g.totalScore += candidates[0].score
+	return 0
+}
+
+func (g *Generator) hasWord(word Word) bool {
+	return slices.IndexFunc(g.placedWords, func(placement Placement) bool {
+		return word.Word == placement.Word.Word
+	}) > -1
+}
+
+type candidate struct {
+	Placement
+	score int
+}
+
+// candidates scores the valid placements and orders them from highest to lowest score.
+// Placements with equal scores keep their original order.
+func (g *Generator) candidates(placements []Placement, scorer Scorer) []candidate {
+	var candidates []candidate
+	for _, pl := range placements {
+		// placements must be valid and intersect at least one other word before being scored
+		if g.scorePlacement(pl) < 2 {
+			continue
 		}
-		if !options.runAllAttempts {
-			if bestCrossword != nil && (len(words) == len(bestCrossword.Words)) {
-				return bestCrossword
-			}
+		candidates = append(candidates, candidate{Placement: pl, score: scorer.Score(g.grid, pl)})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	return candidates
+}
+
+func (g *Generator) clone() *Generator {
+	grid := make(Grid, len(g.grid))
+	for y := range g.grid {
+		grid[y] = slices.Clone(g.grid[y])
+	}
+	return &Generator{
+		width:       g.width,
+		height:      g.height,
+		grid:        grid,
+		mask:        g.mask,
+		placedWords: slices.Clone(g.placedWords),
+		totalScore:  g.totalScore,
+		unplaced:    slices.Clone(g.unplaced),
+	}
+}
+
+// validatePinned checks the pinned placements fit in the grid and do not collide with each other.
+func (g *Generator) validatePinned(pinned []Placement) error {
+	pins := NewRectGenerator(g.width, g.height)
+	pins.mask = g.mask
+	for _, pl := range pinned {
+		if pl.Word.Word == "" {
+			return fmt.Errorf("%w: empty word at %d,%d", ErrInvalidPinnedPlacement, pl.X, pl.Y)
+		}
+		if !pins.fits(pl) {
+			return fmt.Errorf("%w: %s at %d,%d does not fit in the grid or mask", ErrInvalidPinnedPlacement, pl.Word.Word, pl.X, pl.Y)
 		}
+		if pins.scorePlacement(pl) == 0 {
+			return fmt.Errorf("%w: %s at %d,%d collides with another word", ErrInvalidPinnedPlacement, pl.Word.Word, pl.X, pl.Y)
+		}
+		pins.placeWord(pl)
 	}
+	return nil
+}
+
+func (g *Generator) crossword() *Crossword {
+	return &Crossword{
+		Words:      g.placedWords,
+		Grid:       g.grid,
+		TotalScore: g.totalScore,
+		Mask:       g.mask,
+		Report:     &GenerationReport{Unplaced: g.unplaced},
+	}
+}
 
-	return bestCrossword
+func (g *Generator) reset() {
+	g.grid = NewRectGrid(g.width, g.height)
+	g.placedWords = nil
+	g.totalScore = 0
+	g.unplaced = nil
 }
 
 func (g *Generator) placeWord(placement Placement) {
-	for c := range len(placement.Word.Word) {
+	for c, char := range placement.Word.chars() {
 		// don't bother checking if the word fits since this should already happen
 		// in suggestPlacements
 		if !placement.Vertical {
-			g.grid[placement.Y][placement.X+c] = Cell{Char: rune(placement.Word.Word[c]), CharIdx: c}
+			g.grid[placement.Y][placement.X+c] = Cell{Char: char, CharIdx: c}
 		} else {
-			g.grid[placement.Y+c][placement.X] = Cell{Char: rune(placement.Word.Word[c]), CharIdx: c}
+			g.grid[placement.Y+c][placement.X] = Cell{Char: char, CharIdx: c}
 		}
 	}
 	placement.ID = len(g.placedWords) + 1
@@ -191,30 +624,37 @@ func (g *Generator) placeWord(placement Placement) {
 }
 
 func (g *Generator) suggestPlacements(word Word) []Placement {
+	chars := word.chars()
 	var placements []Placement
-	for charIdx := range len(word.Word) {
-		for y := range g.gridSize {
-			for x := range g.gridSize {
+	for charIdx := range len(chars) {
+		for y := range g.height {
+			for x := range g.width {
 				// word intersects existing cell
-				if g.grid[y][x].Char == rune(word.Word[charIdx]) {
+				if g.grid[y][x].Char == chars[charIdx] {
 					// check vertical fit.
 					{
-						if y-charIdx >= 0 && y+(len(word.Word)-(charIdx+1)) < g.gridSize {
-							placements = append(placements, Placement{
+						if y-charIdx >= 0 && y+(len(chars)-(charIdx+1)) < g.height {
+							pl := Placement{
 								Word:     word,
 								X:        x,
 								Y:        y - charIdx,
 								Vertical: true,
-							})
+							}
+							if g.mask.allows(pl) {
+								placements = append(placements, pl)
+							}
 						}
 					}
 					// check horizontal fit.
-					if x-charIdx >= 0 && x+(len(word.Word)-(charIdx+1)) < g.gridSize {
-						placements = append(placements, Placement{
+					if x-charIdx >= 0 && x+(len(chars)-(charIdx+1)) < g.width {
+						pl := Placement{
 							Word: word,
 							X:    x - charIdx,
 							Y:    y,
-						})
+						}
+						if g.mask.allows(pl) {
+							placements = append(placements, pl)
+						}
 					}
 				}
 			}
@@ -224,14 +664,15 @@ func (g *Generator) suggestPlacements(word Word) []Placement {
 }
 
 func (g *Generator) scorePlacement(pl Placement) int {
+	chars := pl.Word.chars()
 	score := 1
 	// word overflows grid
-	if (!pl.Vertical && pl.X+len(pl.Word.Word)-1 > g.gridSize) || (pl.Vertical && pl.Y+len(pl.Word.Word)-1 > g.gridSize) {
+	if !g.fits(pl) {
 		return 0
 	}
 	// horizontal checking
 	if !pl.Vertical {
-		for charIdx := range len(pl.Word.Word) {
+		for charIdx := range len(chars) {
 
 			// if the word doesn't start at the edge of the board...
 			if charIdx == 0 && pl.X > 0 {
@@ -241,16 +682,16 @@ func (g *Generator) scorePlacement(pl Placement) int {
 				}
 			}
 			// if the word doesn't end at the edge of the board...
-			if charIdx == len(pl.Word.Word)-1 && (pl.X+len(pl.Word.Word)) < len(g.grid[pl.Y]) {
+			if charIdx == len(chars)-1 && (pl.X+len(chars)) < len(g.grid[pl.Y]) {
 				// check following cell for collision
-				if !g.grid[pl.Y][pl.X+len(pl.Word.Word)].Empty() {
+				if !g.grid[pl.Y][pl.X+len(chars)].Empty() {
 					return 0
 				}
 			}
 
 			// increase score for any valid overlaps
 			nextCellInGrid := g.grid[pl.Y][pl.X+charIdx]
-			if rune(pl.Word.Word[charIdx]) == nextCellInGrid.Char {
+			if chars[charIdx] == nextCellInGrid.Char {
 				score += 1
 			} else if !nextCellInGrid.Empty() {
 				return 0
@@ -259,12 +700,12 @@ func (g *Generator) scorePlacement(pl Placement) int {
 				if pl.Y > 0 && !g.grid[pl.Y-1][pl.X+charIdx].Empty() {
 					return 0
 				}
-				if pl.Y < g.gridSize-1 && !g.grid[pl.Y+1][pl.X+charIdx].Empty() {
+				if pl.Y < g.height-1 && !g.grid[pl.Y+1][pl.X+charIdx].Empty() {
 					return 0
 				}
 			}
 			// check the next cell to the last char
-			if charIdx == (len(pl.Word.Word) - 1) {
+			if charIdx == (len(chars) - 1) {
 				nextCellIdx := pl.X + charIdx + 1
 				if nextCellIdx < len(g.grid[pl.Y]) && !g.grid[pl.Y][nextCellIdx].Empty() {
 					return 0
@@ -272,7 +713,7 @@ func (g *Generator) scorePlacement(pl Placement) int {
 			}
 		}
 	} else {
-		for charIdx := range len(pl.Word.Word) {
+		for charIdx := range len(chars) {
 			// if the word doesn't start at the top of the board...
 			if charIdx == 0 && pl.Y > 0 {
 				// check preceding cell for collision
@@ -281,16 +722,16 @@ func (g *Generator) scorePlacement(pl Placement) int {
 				}
 			}
 			// if the word doesn't end at the edge of the board...
-			if charIdx == len(pl.Word.Word)-1 && (pl.Y+len(pl.Word.Word)-1) < len(g.grid[pl.X])-1 {
+			if charIdx == len(chars)-1 && (pl.Y+len(chars)) < g.height {
 				// check following cell for collision
-				if !g.grid[pl.Y+len(pl.Word.Word)][pl.X].Empty() {
+				if !g.grid[pl.Y+len(chars)][pl.X].Empty() {
 					return 0
 				}
 			}
 
 			// increase score for any valid overlaps
 			nextCellInGrid := g.grid[pl.Y+charIdx][pl.X]
-			if rune(pl.Word.Word[charIdx]) == nextCellInGrid.Char {
+			if chars[charIdx] == nextCellInGrid.Char {
 				score += 1
 			} else if !nextCellInGrid.Empty() {
 				return 0
@@ -303,7 +744,7 @@ func (g *Generator) scorePlacement(pl Placement) int {
 				}
 
 				// right
-				if pl.X < g.gridSize-1 && !g.grid[pl.Y+charIdx][pl.X+1].Empty() {
+				if pl.X < g.width-1 && !g.grid[pl.Y+charIdx][pl.X+1].Empty() {
 					return 0
 				}
 			}
@@ -312,11 +753,55 @@ func (g *Generator) scorePlacement(pl Placement) int {
 	return score
 }
 
+// fits checks the placement is entirely within the bounds of the grid and only uses cells
+// allowed by the mask.
+func (g *Generator) fits(pl Placement) bool {
+	if pl.X < 0 || pl.Y < 0 {
+		return false
+	}
+	if pl.Vertical {
+		return pl.X < g.width && pl.Y+pl.Word.Len() <= g.height && g.mask.allows(pl)
+	}
+	return pl.Y < g.height && pl.X+pl.Word.Len() <= g.width && g.mask.allows(pl)
+}
+
+// firstPlacement finds the first position in reading order where the word fits horizontally.
+// Without a mask this is always the top left corner of the grid.
+func (g *Generator) firstPlacement(word Word) Placement {
+	for y := range g.height {
+		for x := range g.width {
+			if pl := (Placement{ID: 1, Word: word, X: x, Y: y}); g.fits(pl) {
+				return pl
+			}
+		}
+	}
+	return Placement{ID: 1, Word: word}
+}
+
+func checkRequiredWords(cw *Crossword, words []Word) error {
+	placed := map[string]bool{}
+	if cw != nil {
+		for _, pl := range cw.Words {
+			placed[pl.Word.Word] = true
+		}
+	}
+	var missing []string
+	for _, w := range words {
+		if w.Required && !placed[w.Word] {
+			missing = append(missing, w.Word)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequiredWordsNotPlaced, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func countLetters(wordStr string) []int {
 	words := strings.Split(wordStr, " ")
 	counts := make([]int, len(words))
 	for k, word := range words {
-		counts[k] = len(word)
+		counts[k] = utf8.RuneCountInString(word)
 	}
 	return counts
 }
@@ -1,11 +1,14 @@
 package crossword
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math/rand/v2"
 	"strings"
 	"testing"
+	"time"
 )
 
 func word(chars string) Word {
@@ -193,8 +196,9 @@ func TestGenerator_suggestCoordinates(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Generator{
-				gridSize: tt.fields.gridSize,
-				grid:     tt.fields.grid,
+				width:  tt.fields.gridSize,
+				height: tt.fields.gridSize,
+				grid:   tt.fields.grid,
 			}
 			for _, v := range tt.existingWords {
 				g.placeWord(v)
@@ -802,3 +806,222 @@ FO+AR!
 		})
 	}
 }
+
+func TestGenerator_GenerateContext(t *testing.T) {
+	t.Run("cancelled context returns before first pass", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cw, err := NewGenerator(4).GenerateContext(ctx, []Word{{Word: "food"}, {Word: "fud"}}, 1)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, cw)
+	})
+	t.Run("no attempts", func(t *testing.T) {
+		for _, attempts := range []int{0, -1} {
+			cw, err := NewGenerator(4).GenerateContext(context.Background(), []Word{{Word: "food"}, {Word: "fud"}}, attempts)
+			require.NoError(t, err)
+			require.Nil(t, cw)
+		}
+		require.Nil(t, NewSearchGenerator(4).Generate([]Word{{Word: "food"}}, -1))
+	})
+	t.Run("completed generation returns no error", func(t *testing.T) {
+		cw, err := NewGenerator(4).GenerateContext(context.Background(), []Word{{Word: "food"}, {Word: "fud"}}, 1)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 2)
+	})
+	t.Run("time budget returns best crossword so far", func(t *testing.T) {
+		words := make([]Word, 200)
+		for k := range words {
+			words[k] = Word{Word: fmt.Sprintf("word%dxyz", k)}
+		}
+		cw, err := NewGenerator(25).GenerateContext(
+			context.Background(),
+			words,
+			1000,
+			WithAllAttempts(true),
+			WithTimeBudget(time.Millisecond),
+		)
+		require.ErrorIs(t, err, ErrTimeBudgetExceeded)
+		require.NotNil(t, cw)
+		require.NotEmpty(t, cw.Words)
+	})
+}
+
+func TestGenerator_Generate_seeded(t *testing.T) {
+	newWords := func() []Word {
+		return []Word{{Word: "food"}, {Word: "fud"}, {Word: "duff"}, {Word: "dough"}, {Word: "huge"}, {Word: "edge"}, {Word: "ogre"}}
+	}
+	first := NewGenerator(10).Generate(newWords(), 5, WithSeed(42), WithAllAttempts(true))
+	second := NewGenerator(10).Generate(newWords(), 5, WithSeed(*first.Seed), WithAllAttempts(true))
+
+	require.EqualValues(t, uint64(42), *first.Seed)
+	require.EqualValues(t, first, second)
+	require.EqualValues(t, RenderText(first, WithAllSolved(true)), RenderText(second, WithAllSolved(true)))
+
+	// a zero seed is recorded but there is no seed when the random source is given
+	require.EqualValues(t, uint64(0), *NewGenerator(10).Generate(newWords(), 1, WithSeed(0)).Seed)
+	require.NotNil(t, NewGenerator(10).Generate(newWords(), 1).Seed)
+	require.Nil(t, NewGenerator(10).Generate(newWords(), 1, WithRand(rand.New(rand.NewPCG(1, 2)))).Seed)
+}
+
+func TestGenerator_Generate_concurrent(t *testing.T) {
+	newWords := func() []Word {
+		return []Word{{Word: "food"}, {Word: "fud"}, {Word: "duff"}, {Word: "dough"}, {Word: "huge"}, {Word: "edge"}, {Word: "ogre"}, {Word: "gruff"}}
+	}
+	sequential := NewGenerator(10).Generate(newWords(), 20, WithSeed(7), WithAllAttempts(true))
+	concurrent := NewGenerator(10).Generate(newWords(), 20, WithSeed(7), WithAllAttempts(true), WithConcurrency(4))
+
+	require.EqualValues(t, sequential, concurrent)
+}
+
+func TestGenerator_Generate_rectangular(t *testing.T) {
+	cw := NewRectGenerator(6, 3).Generate([]Word{{Word: "foobar"}, {Word: "bud"}, {Word: "rat"}}, 1)
+	cw.Solve()
+	require.EqualValues(t, strings.TrimSpace(`
+FOOBAR
+###U#A
+###D#T`), strings.TrimSpace(RenderText(cw)))
+}
+
+func TestGenerator_Generate_report(t *testing.T) {
+	cw := NewRectGenerator(4, 1).Generate([]Word{{Word: "food"}, {Word: "food"}, {Word: "xyz"}, {Word: "od"}, {Word: "dxxxxx"}}, 1)
+	require.Len(t, cw.Words, 1)
+	require.EqualValues(t, &GenerationReport{
+		Unplaced: []UnplacedWord{
+			{Word: Word{Word: "FOOD", LettersCounts: []int{4}}, Reason: UnplacedDuplicate},
+			{Word: Word{Word: "XYZ", LettersCounts: []int{3}}, Reason: UnplacedNoSharedLetters},
+			{Word: Word{Word: "OD", LettersCounts: []int{2}}, Reason: UnplacedCollision},
+			{Word: Word{Word: "DXXXXX", LettersCounts: []int{6}}, Reason: UnplacedOverflow},
+		},
+	}, cw.Report)
+}
+
+func TestGenerator_Generate_cropAndCentre(t *testing.T) {
+	words := func() []Word {
+		return []Word{{Word: "foobar"}, {Word: "bud"}, {Word: "rat"}}
+	}
+	t.Run("crop", func(t *testing.T) {
+		cw := NewGenerator(10).Generate(words(), 1, WithCrop(true))
+		cw.Solve()
+		require.EqualValues(t, strings.TrimSpace(`
+FOOBAR
+###U#A
+###D#T`), strings.TrimSpace(RenderText(cw)))
+	})
+	t.Run("centre", func(t *testing.T) {
+		cw := NewRectGenerator(8, 5).Generate(words(), 1, WithCentre(true))
+		cw.Solve()
+		require.EqualValues(t, strings.TrimSpace(`
+########
+#FOOBAR#
+####U#A#
+####D#T#
+########`), strings.TrimSpace(RenderText(cw)))
+		require.EqualValues(t, 1, cw.Words[0].X)
+		require.EqualValues(t, 1, cw.Words[0].Y)
+	})
+}
+
+func TestGenerator_GenerateContext_requiredAndPriority(t *testing.T) {
+	// only a single word can fit in a grid with one row, so which is chosen depends on the word weights.
+	t.Run("longest word is chosen by default", func(t *testing.T) {
+		cw, err := NewRectGenerator(5, 1).GenerateContext(context.Background(), []Word{{Word: "abc"}, {Word: "de"}}, 1)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 1)
+		require.EqualValues(t, "ABC", cw.Words[0].Word.Word)
+	})
+	t.Run("priority word is preferred", func(t *testing.T) {
+		cw, err := NewRectGenerator(5, 1).GenerateContext(context.Background(), []Word{{Word: "abc"}, {Word: "de", Priority: 1}}, 1)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 1)
+		require.EqualValues(t, "DE", cw.Words[0].Word.Word)
+	})
+	t.Run("required word is preferred over priority", func(t *testing.T) {
+		cw, err := NewRectGenerator(5, 1).GenerateContext(context.Background(), []Word{{Word: "abc", Required: true}, {Word: "de", Priority: 5}}, 1)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 1)
+		require.EqualValues(t, "ABC", cw.Words[0].Word.Word)
+	})
+	t.Run("error if required word cannot be placed", func(t *testing.T) {
+		cw, err := NewRectGenerator(5, 1).GenerateContext(context.Background(), []Word{{Word: "abc", Required: true}, {Word: "de", Required: true}}, 1)
+		require.ErrorIs(t, err, ErrRequiredWordsNotPlaced)
+		require.ErrorContains(t, err, "DE")
+		require.NotNil(t, cw)
+		require.Len(t, cw.Words, 1)
+	})
+}
+
+func TestGenerator_GenerateContext_pinned(t *testing.T) {
+	t.Run("words are attached to pinned placements", func(t *testing.T) {
+		cw, err := NewRectGenerator(6, 5).GenerateContext(
+			context.Background(),
+			[]Word{{Word: "bud"}, {Word: "foobar"}, {Word: "tar"}},
+			1,
+			WithPinned(Placement{Word: Word{Word: "foo bar"}, X: 0, Y: 2}),
+		)
+		require.NoError(t, err)
+		require.Empty(t, cw.Report.Unplaced)
+		require.EqualValues(t, Placement{ID: 1, Word: Word{Word: "FOOBAR", LettersCounts: []int{3, 3}}, X: 0, Y: 2}, cw.Words[0])
+		cw.Solve()
+		require.EqualValues(t, strings.TrimSpace(`
+#####T
+#####A
+FOOBAR
+###U##
+###D##`), strings.TrimSpace(RenderText(cw)))
+	})
+	t.Run("pinned placements without words", func(t *testing.T) {
+		cw, err := NewGenerator(3).GenerateContext(context.Background(), nil, 1, WithPinned(Placement{Word: word("foo"), Y: 1}))
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 1)
+	})
+	t.Run("pinned placement must fit in the grid", func(t *testing.T) {
+		_, err := NewGenerator(3).GenerateContext(context.Background(), nil, 1, WithPinned(Placement{Word: word("foo"), X: 1}))
+		require.ErrorIs(t, err, ErrInvalidPinnedPlacement)
+	})
+	t.Run("pinned placements must not collide", func(t *testing.T) {
+		_, err := NewGenerator(3).GenerateContext(context.Background(), nil, 1, WithPinned(
+			Placement{Word: word("foo")},
+			Placement{Word: word("bar"), Vertical: true},
+		))
+		require.ErrorIs(t, err, ErrInvalidPinnedPlacement)
+	})
+}
+
+func TestGenerator_Generate_unicode(t *testing.T) {
+	t.Run("accented words cross", func(t *testing.T) {
+		// the second word uses a combining accent which should be composed into a single character
+		cw := NewGenerator(4).Generate([]Word{{Word: "café"}, {Word: "e\u0301lan"}}, 1)
+		cw.Solve()
+		require.EqualValues(t, strings.TrimSpace(`
+CAFÉ
+###L
+###A
+###N`), strings.TrimSpace(RenderText(cw)))
+		require.EqualValues(t, "4", cw.Words[1].Word.LetterCountStr())
+	})
+	t.Run("greek and cyrillic words are kept", func(t *testing.T) {
+		cw := NewGenerator(5).Generate([]Word{{Word: "άλφα"}, {Word: "φως!"}, {Word: "мир"}}, 1)
+		cw.Solve()
+		require.EqualValues(t, strings.TrimSpace(`
+ΆΛΦΑ#
+##Ω##
+##Σ##
+#####
+#####`), strings.TrimSpace(RenderText(cw)))
+		require.EqualValues(t, []UnplacedWord{{Word: Word{Word: "МИР", LettersCounts: []int{3}}, Reason: UnplacedNoSharedLetters}}, cw.Report.Unplaced)
+	})
+	t.Run("character hints use character positions", func(t *testing.T) {
+		cw := NewGenerator(8).Generate([]Word{{Word: "één twee"}}, 1, WithRevealFirstLetterOfEachWord(true))
+		require.EqualValues(t, []int{0, 3}, cw.Words[0].Word.CharacterHints)
+		require.EqualValues(t, []int{3, 4}, cw.Words[0].Word.LettersCounts)
+		require.EqualValues(t, "É??T???#", strings.Split(RenderText(cw), "\n")[0])
+	})
+	t.Run("alphabet restricts characters", func(t *testing.T) {
+		cw := NewGenerator(4).Generate([]Word{{Word: "café"}}, 1, WithAlphabet(AlphabetLatin))
+		require.EqualValues(t, "CAF", cw.Words[0].Word.Word)
+	})
+	t.Run("alphabet casing", func(t *testing.T) {
+		cw := NewGenerator(8).Generate([]Word{{Word: "istanbul"}}, 1, WithAlphabet(AlphabetTurkish))
+		require.EqualValues(t, "İSTANBUL", cw.Words[0].Word.Word)
+	})
+}
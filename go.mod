@@ -4,10 +4,12 @@ go 1.26.2
 
 require (
 	github.com/fogleman/gg v1.3.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/stretchr/testify v1.11.1
 	github.com/warmans/vue v1.0.0
 	golang.org/x/image v0.39.0
+	golang.org/x/text v0.36.0
 )
 
 require (
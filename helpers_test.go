@@ -0,0 +1,38 @@
+package crossword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCrossword generates a small crossword with clues which is used as the input of the
+// rendering and file format tests.
+func testCrossword(t *testing.T) *Crossword {
+	t.Helper()
+	cw := NewGenerator(10).Generate([]Word{
+		{Word: "apple", Clue: "apple clue"},
+		{Word: "pear", Clue: "pear clue"},
+		{Word: "plum", Clue: "plum clue"},
+		{Word: "lemon", Clue: "lemon clue"},
+		{Word: "melon", Clue: "melon clue"},
+	}, 5, WithSeed(1), WithCrop(true))
+	require.Empty(t, cw.Report.Unplaced)
+	return cw
+}
+
+// placedClue is the position and text of a placement, for comparing crosswords read from a file.
+type placedClue struct {
+	X, Y     int
+	Vertical bool
+	Word     string
+	Clue     string
+}
+
+func placedClues(cw *Crossword) []placedClue {
+	var clues []placedClue
+	for _, pl := range cw.Words {
+		clues = append(clues, placedClue{X: pl.X, Y: pl.Y, Vertical: pl.Vertical, Word: pl.Word.Word, Clue: pl.Word.Clue})
+	}
+	return clues
+}
@@ -0,0 +1,420 @@
+package crossword
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// WithObfuscatedSolution hides the solution in the HTML written by RenderHTML so it cannot be read
+// from the page source at a glance. It is not encryption: the key is in the page.
+func WithObfuscatedSolution(obfuscate bool) RenderOption {
+	return func(opts *renderOpts) {
+		opts.obfuscateSolution = obfuscate
+	}
+}
+
+type htmlPuzzle struct {
+	Title     string
+	Author    string
+	Copyright string
+	Notes     string
+	Width     int
+	Rows      [][]htmlCell
+	Groups    []htmlClueGroup
+	Colors    map[string]template.CSS
+	Fonts     map[string]template.CSS
+	FontFaces template.CSS
+	FontSize  template.CSS
+	Data      htmlData
+}
+
+type htmlCell struct {
+	X, Y      int
+	Block     bool
+	Void      bool
+	Labels    []string
+	Value     string
+	Circled   bool
+	MaxLength int
+}
+
+type htmlClueGroup struct {
+	Title string
+	Clues []htmlClue
+}
+
+type htmlClue struct {
+	ID          string
+	Clue        string
+	Enumeration string
+	Solved      bool
+}
+
+// htmlData is the puzzle data used by the script.
+type htmlData struct {
+	Width    int        `json:"width"`
+	Words    []htmlWord `json:"words"`
+	Solution string     `json:"solution"`
+	Key      string     `json:"key,omitempty"`
+}
+
+type htmlWord struct {
+	ID       string   `json:"id"`
+	Vertical bool     `json:"vertical"`
+	Cells    [][2]int `json:"cells"`
+}
+
+// RenderHTML writes the crossword as a single HTML page with inline CSS and JavaScript so it can be
+// played in a browser. Letters are typed into the grid, clicking a clue highlights its cells and the
+// Check and Reveal buttons compare the entries with the solution stored in the page. Solved words and
+// hints are filled in and cannot be changed. The page uses the same colors and fonts as RenderImage.
+func RenderHTML(w io.Writer, c *Crossword, opts ...RenderOption) error {
+	options := resolveRenderOptions(opts...)
+	if err := options.loadFonts(); err != nil {
+		return err
+	}
+	applyRandomSolved(c, options)
+	letterFamily, labelFamily, clueFamily, fontFaces := options.cssFonts()
+
+	puzzle := htmlPuzzle{
+		Title:     c.Metadata.Title,
+		Author:    c.Metadata.Author,
+		Copyright: c.Metadata.Copyright,
+		Notes:     c.Metadata.Notes,
+		Width:     c.Grid.Width(),
+		Rows:      make([][]htmlCell, c.Grid.Height()),
+		Colors: map[string]template.CSS{
+			"background":      cssColor(options.backgroundColor),
+			"word-background": cssColor(options.wordBackgroundColor),
+			"word":            cssColor(options.wordColor),
+			"label":           cssColor(options.labelColor),
+			"clue":            cssColor(options.clueColor),
+		},
+		Fonts: map[string]template.CSS{
+			"letter-font": template.CSS(letterFamily),
+			"label-font":  template.CSS(labelFamily),
+			"clue-font":   template.CSS(clueFamily),
+		},
+		FontFaces: template.CSS(fontFaces),
+		FontSize:  template.CSS(fmt.Sprintf("%g", options.wordFontSizePcnt)),
+		Data:      htmlData{Width: c.Grid.Width()},
+	}
+	if puzzle.Title == "" {
+		puzzle.Title = "Crossword"
+	}
+
+	solution := make([]string, c.Grid.Width()*c.Grid.Height())
+	for y := range c.Grid {
+		puzzle.Rows[y] = make([]htmlCell, c.Grid.Width())
+		for x, cell := range c.Grid[y] {
+			hc := htmlCell{X: x, Y: y, Void: !c.Mask.Allowed(x, y), Block: cell.Empty(), Circled: cell.Circled}
+			if !hc.Void && !hc.Block {
+				text := strings.ToUpper(c.cellText(x, y, options.originalSpelling))
+				solution[y*c.Grid.Width()+x] = text
+				hc.MaxLength = len([]rune(text))
+				if c.revealed(x, y) || options.solveAll {
+					hc.Value = text
+				}
+				for _, pl := range c.CellPlacements(x, y) {
+					if pl.X == x && pl.Y == y {
+						hc.Labels = append(hc.Labels, pl.ClueID())
+					}
+				}
+			}
+			puzzle.Rows[y][x] = hc
+		}
+	}
+
+	across, down := htmlClueGroup{Title: "Across"}, htmlClueGroup{Title: "Down"}
+	for _, pl := range c.Words {
+		word := htmlWord{ID: pl.ClueID(), Vertical: pl.Vertical}
+		for charIdx := range pl.Word.Len() {
+			x, y := pl.cell(charIdx)
+			word.Cells = append(word.Cells, [2]int{x, y})
+		}
+		puzzle.Data.Words = append(puzzle.Data.Words, word)
+
+		clue := htmlClue{ID: pl.ClueID(), Clue: pl.Word.Clue, Enumeration: pl.Word.LetterCountStr(), Solved: pl.Solved}
+		if pl.Vertical {
+			down.Clues = append(down.Clues, clue)
+		} else {
+			across.Clues = append(across.Clues, clue)
+		}
+	}
+	puzzle.Groups = []htmlClueGroup{across, down}
+
+	encoded, err := json.Marshal(solution)
+	if err != nil {
+		return err
+	}
+	if options.obfuscateSolution {
+		key := make([]byte, 16)
+		for k := range key {
+			key[k] = byte(options.rand.UintN(256))
+		}
+		for k := range encoded {
+			encoded[k] ^= key[k%len(key)]
+		}
+		puzzle.Data.Key = hex.EncodeToString(key)
+	}
+	puzzle.Data.Solution = base64.StdEncoding.EncodeToString(encoded)
+
+	if err := htmlTemplate.Execute(w, puzzle); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
+
+// cssColor formats the color as a CSS rgba() value.
+func cssColor(cl color.Color) template.CSS {
+	nrgba := color.NRGBAModel.Convert(cl).(color.NRGBA)
+	return template.CSS(fmt.Sprintf("rgba(%d, %d, %d, %g)", nrgba.R, nrgba.G, nrgba.B, float64(nrgba.A)/255))
+}
+
+var htmlTemplate = template.Must(template.New("crossword").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+{{.FontFaces -}}
+:root {
+{{- range $name, $value := .Colors}}
+  --{{$name}}: {{$value}};
+{{- end}}
+{{- range $name, $value := .Fonts}}
+  --{{$name}}: {{$value}};
+{{- end}}
+  --cell: min(2.5rem, calc((100vw - 2rem) / {{.Width}}));
+  --highlight: rgba(255, 215, 0, 0.35);
+  --current: rgba(255, 215, 0, 0.8);
+}
+body { margin: 1rem; background: var(--background); color: var(--clue); font-family: var(--clue-font); }
+h1 { margin: 0 0 0.25rem; font-size: 1.5rem; }
+.byline, .notes { margin: 0 0 1rem; }
+.puzzle { display: flex; flex-wrap: wrap; gap: 2rem; align-items: flex-start; }
+.grid { display: grid; grid-template-columns: repeat({{.Width}}, var(--cell)); gap: 1px; width: max-content; }
+.cell { position: relative; width: var(--cell); height: var(--cell); }
+.cell.letter { background: var(--word-background); }
+.cell.void { visibility: hidden; }
+.cell input { box-sizing: border-box; width: 100%; height: 100%; padding: 0; border: 0; background: transparent; color: var(--word); font-family: var(--letter-font); font-size: calc(var(--cell) * {{.FontSize}}); text-align: center; text-transform: uppercase; caret-color: transparent; outline: none; }
+.cell.highlight { background: var(--highlight); }
+.cell.current { background: var(--current); }
+.cell.wrong input { color: #d00; }
+.cell.revealed input { color: var(--label); }
+.cell .label { position: absolute; left: 2px; font-size: calc(var(--cell) * 0.25); line-height: 1; color: var(--label); font-family: var(--label-font); pointer-events: none; }
+.cell .label:first-child { top: 1px; }
+.cell .label + .label { bottom: 1px; }
+.cell.circled::after { content: ""; position: absolute; inset: 5%; border: 1px solid var(--word); border-radius: 50%; pointer-events: none; }
+.clues { display: flex; flex-wrap: wrap; gap: 2rem; }
+.clues h2 { margin: 0 0 0.5rem; font-size: 1.1rem; text-transform: uppercase; }
+.clues ol { margin: 0; padding: 0; list-style: none; max-width: 20rem; }
+.clues li { margin-bottom: 0.4rem; cursor: pointer; }
+.clues li.highlight { background: var(--highlight); color: var(--word); }
+.clues li.solved { text-decoration: line-through; }
+.clue-id { font-weight: bold; }
+.controls { margin-top: 1rem; display: flex; gap: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Author}}
+<p class="byline">{{.Author}}{{if .Copyright}} &middot; {{.Copyright}}{{end}}</p>
+{{- else if .Copyright}}
+<p class="byline">{{.Copyright}}</p>
+{{- end}}
+{{- if .Notes}}
+<p class="notes">{{.Notes}}</p>
+{{- end}}
+<div class="puzzle">
+<div>
+<div class="grid" id="grid">
+{{- range .Rows}}{{range .}}
+{{- if .Void}}
+<div class="cell void" id="cell-{{.X}}-{{.Y}}"></div>
+{{- else if .Block}}
+<div class="cell block" id="cell-{{.X}}-{{.Y}}"></div>
+{{- else}}
+<div class="cell letter{{if .Circled}} circled{{end}}{{if .Value}} revealed{{end}}" id="cell-{{.X}}-{{.Y}}">{{range .Labels}}<span class="label">{{.}}</span>{{end}}<input data-x="{{.X}}" data-y="{{.Y}}" maxlength="{{.MaxLength}}" autocomplete="off" autocapitalize="characters" spellcheck="false"{{if .Value}} value="{{.Value}}" readonly{{end}}></div>
+{{- end}}
+{{- end}}{{end}}
+</div>
+<div class="controls">
+<button type="button" id="check">Check</button>
+<button type="button" id="reveal-word">Reveal word</button>
+<button type="button" id="reveal-all">Reveal all</button>
+</div>
+</div>
+<div class="clues">
+{{- range .Groups}}
+<div>
+<h2>{{.Title}}</h2>
+<ol>
+{{- range .Clues}}
+<li id="clue-{{.ID}}" data-clue="{{.ID}}"{{if .Solved}} class="solved"{{end}}><span class="clue-id">{{.ID}}</span> {{.Clue}} <span class="enumeration">[{{.Enumeration}}]</span></li>
+{{- end}}
+</ol>
+</div>
+{{- end}}
+</div>
+</div>
+<script>
+(function () {
+  const puzzle = {{.Data}};
+  const solution = (function () {
+    let bytes = Uint8Array.from(atob(puzzle.solution), (c) => c.charCodeAt(0));
+    if (puzzle.key) {
+      const key = puzzle.key.match(/../g).map((h) => parseInt(h, 16));
+      bytes = bytes.map((b, i) => b ^ key[i % key.length]);
+    }
+    return JSON.parse(new TextDecoder().decode(bytes));
+  })();
+
+  const pos = (x, y) => x + "," + y;
+  const inputs = {};
+  document.querySelectorAll("#grid input").forEach((input) => {
+    inputs[pos(input.dataset.x, input.dataset.y)] = input;
+  });
+  const cellWords = {};
+  const wordsByID = {};
+  puzzle.words.forEach((word) => {
+    wordsByID[word.id] = word;
+    word.cells.forEach(([x, y]) => (cellWords[pos(x, y)] = cellWords[pos(x, y)] || []).push(word));
+  });
+  const answer = (input) => solution[input.dataset.y * puzzle.width + Number(input.dataset.x)];
+
+  let current = null;
+  let vertical = false;
+
+  function select(word) {
+    document.querySelectorAll(".highlight, .current").forEach((el) => el.classList.remove("highlight", "current"));
+    current = word;
+    if (!word) {
+      return;
+    }
+    vertical = word.vertical;
+    word.cells.forEach(([x, y]) => document.getElementById("cell-" + x + "-" + y).classList.add("highlight"));
+    document.getElementById("clue-" + word.id).classList.add("highlight");
+  }
+
+  function markCurrent(input) {
+    document.querySelectorAll(".cell.current").forEach((el) => el.classList.remove("current"));
+    input.parentElement.classList.add("current");
+  }
+
+  function focusCell(input) {
+    input.focus();
+    markCurrent(input);
+  }
+
+  // step moves along the current word, optionally skipping cells which are already revealed.
+  function step(input, delta, skipRevealed) {
+    if (!current) {
+      return;
+    }
+    let idx = current.cells.findIndex(([x, y]) => x == input.dataset.x && y == input.dataset.y) + delta;
+    while (skipRevealed && current.cells[idx] && inputs[pos(...current.cells[idx])].readOnly) {
+      idx += delta;
+    }
+    if (current.cells[idx]) {
+      focusCell(inputs[pos(...current.cells[idx])]);
+    }
+  }
+
+  Object.values(inputs).forEach((input) => {
+    const words = cellWords[pos(input.dataset.x, input.dataset.y)] || [];
+    input.addEventListener("focus", () => {
+      if (!current || !words.includes(current)) {
+        select(words.find((w) => w.vertical === vertical) || words[0]);
+      }
+      markCurrent(input);
+    });
+    input.addEventListener("mousedown", () => {
+      // clicking the focused cell again switches direction
+      if (document.activeElement === input && words.length > 1) {
+        select(words.find((w) => w !== current));
+      }
+    });
+    input.addEventListener("input", () => {
+      input.value = input.value.toUpperCase();
+      input.parentElement.classList.remove("wrong");
+      if (input.value.length >= input.maxLength) {
+        step(input, 1, true);
+      }
+    });
+    input.addEventListener("keydown", (e) => {
+      const moves = { ArrowLeft: [-1, 0], ArrowRight: [1, 0], ArrowUp: [0, -1], ArrowDown: [0, 1] };
+      if (moves[e.key]) {
+        e.preventDefault();
+        const [dx, dy] = moves[e.key];
+        vertical = dy !== 0;
+        let x = Number(input.dataset.x) + dx;
+        let y = Number(input.dataset.y) + dy;
+        while (x >= 0 && y >= 0 && x < puzzle.width && y < solution.length / puzzle.width) {
+          if (inputs[pos(x, y)]) {
+            const next = inputs[pos(x, y)];
+            const nextWords = cellWords[pos(x, y)] || [];
+            select(nextWords.find((w) => w.vertical === vertical) || nextWords[0]);
+            focusCell(next);
+            return;
+          }
+          x += dx;
+          y += dy;
+        }
+      } else if (e.key === "Backspace" && input.value === "") {
+        e.preventDefault();
+        step(input, -1, false);
+        if (!document.activeElement.readOnly) {
+          document.activeElement.value = "";
+        }
+      } else if (e.key === "Enter" || e.key === "Tab") {
+        e.preventDefault();
+        const idx = puzzle.words.indexOf(current) + (e.shiftKey ? -1 : 1);
+        const word = puzzle.words[(idx + puzzle.words.length) % puzzle.words.length];
+        select(word);
+        focusCell(inputs[pos(word.cells[0][0], word.cells[0][1])]);
+      }
+    });
+  });
+
+  document.querySelectorAll(".clues li").forEach((li) => {
+    li.addEventListener("click", () => {
+      const word = wordsByID[li.dataset.clue];
+      select(word);
+      const empty = word.cells.find(([x, y]) => inputs[pos(x, y)].value === "") || word.cells[0];
+      focusCell(inputs[pos(empty[0], empty[1])]);
+    });
+  });
+
+  function reveal(input) {
+    input.value = answer(input);
+    input.readOnly = true;
+    input.parentElement.classList.remove("wrong");
+    input.parentElement.classList.add("revealed");
+  }
+
+  document.getElementById("check").addEventListener("click", () => {
+    Object.values(inputs).forEach((input) => {
+      input.parentElement.classList.toggle("wrong", input.value !== "" && input.value !== answer(input));
+    });
+  });
+  document.getElementById("reveal-word").addEventListener("click", () => {
+    if (current) {
+      current.cells.forEach(([x, y]) => reveal(inputs[pos(x, y)]));
+    }
+  });
+  document.getElementById("reveal-all").addEventListener("click", () => {
+    Object.values(inputs).forEach(reveal);
+  });
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,71 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// htmlSolution decodes the solution embedded in the page.
+func htmlSolution(t *testing.T, page string) []string {
+	match := regexp.MustCompile(`const puzzle = (.*);\n`).FindStringSubmatch(page)
+	require.Len(t, match, 2)
+	data := htmlData{}
+	require.NoError(t, json.Unmarshal([]byte(match[1]), &data))
+
+	encoded, err := base64.StdEncoding.DecodeString(data.Solution)
+	require.NoError(t, err)
+	if data.Key != "" {
+		key, err := hex.DecodeString(data.Key)
+		require.NoError(t, err)
+		for k := range encoded {
+			encoded[k] ^= key[k%len(key)]
+		}
+	}
+	var solution []string
+	require.NoError(t, json.Unmarshal(encoded, &solution))
+	return solution
+}
+
+func TestRenderHTML(t *testing.T) {
+	cw := testCrossword(t)
+	cw.Words[0].Solved = true
+	cw.Words[0].Word.Clue = "<script>alert(1)</script>"
+	cw.Grid[0][0].Circled = true
+	cw.Metadata = Metadata{Title: "Fruit", Author: "Author"}
+
+	t.Run("plain", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		require.NoError(t, RenderHTML(buff, cw))
+		page := buff.String()
+
+		require.Contains(t, page, "<title>Fruit</title>")
+		require.Contains(t, page, `<div class="cell letter circled revealed" id="cell-0-0">`)
+		require.Contains(t, page, `value="A" readonly`)
+		require.Contains(t, page, `<li id="clue-A1" data-clue="A1" class="solved">`)
+		require.Contains(t, page, "&lt;script&gt;alert(1)&lt;/script&gt;")
+		require.Contains(t, page, `<div class="cell block" id="cell-7-0">`)
+		require.NotContains(t, page, `"key"`)
+
+		solution := htmlSolution(t, page)
+		require.Len(t, solution, cw.Grid.Width()*cw.Grid.Height())
+		require.Equal(t, "APPLE", strings.Join(solution[:5], ""))
+		require.Equal(t, "", solution[7])
+	})
+	t.Run("obfuscated", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		require.NoError(t, RenderHTML(buff, cw, WithObfuscatedSolution(true), WithRenderSeed(1)))
+		page := buff.String()
+
+		plain, err := json.Marshal(htmlSolution(t, page))
+		require.NoError(t, err)
+		require.NotContains(t, page, base64.StdEncoding.EncodeToString(plain))
+		require.Equal(t, "APPLE", strings.Join(htmlSolution(t, page)[:5], ""))
+	})
+}
@@ -0,0 +1,418 @@
+package crossword
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ipuzVersion   = "http://ipuz.org/v2"
+	ipuzKind      = "http://ipuz.org/crossword#1"
+	ipuzBlock     = "#"
+	ipuzEmpty     = "0"
+	ipuzAcross    = "Across"
+	ipuzDown      = "Down"
+	ipuzShapeBG   = "shapebg"
+	ipuzCircle    = "circle"
+	ipuzKindMatch = "http://ipuz.org/crossword"
+)
+
+// ErrInvalidIPUZ is returned by UnmarshalIPUZ when the data is not a valid ipuz crossword.
+var ErrInvalidIPUZ = errors.New("invalid ipuz crossword")
+
+var enumerationSeparator = regexp.MustCompile(`[^0-9]+`)
+
+type ipuz struct {
+	Version    string                `json:"version"`
+	Kind       []string              `json:"kind"`
+	Title      string                `json:"title,omitempty"`
+	Author     string                `json:"author,omitempty"`
+	Copyright  string                `json:"copyright,omitempty"`
+	Notes      string                `json:"notes,omitempty"`
+	Dimensions ipuzDimensions        `json:"dimensions"`
+	Block      string                `json:"block,omitempty"`
+	Empty      json.RawMessage       `json:"empty,omitempty"`
+	Puzzle     [][]ipuzCell          `json:"puzzle"`
+	Solution   [][]*ipuzValue        `json:"solution,omitempty"`
+	Saved      [][]*ipuzValue        `json:"saved,omitempty"`
+	Clues      map[string][]ipuzClue `json:"clues"`
+}
+
+type ipuzDimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ipuzCell is a cell of the puzzle grid. It is either null if the cell is not part of the puzzle, a
+// block, a number or label, or an object with the label along with a given value and style.
+type ipuzCell struct {
+	Omitted bool
+	Label   string
+	Value   string
+	Style   map[string]any
+}
+
+func (c ipuzCell) MarshalJSON() ([]byte, error) {
+	if c.Omitted {
+		return []byte("null"), nil
+	}
+	var label any = c.Label
+	if number, err := strconv.Atoi(c.Label); err == nil {
+		label = number
+	}
+	if c.Value == "" && c.Style == nil {
+		return json.Marshal(label)
+	}
+	return json.Marshal(struct {
+		Cell  any            `json:"cell"`
+		Value string         `json:"value,omitempty"`
+		Style map[string]any `json:"style,omitempty"`
+	}{Cell: label, Value: c.Value, Style: c.Style})
+}
+
+func (c *ipuzCell) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if object, ok := raw.(map[string]any); ok {
+		c.Label = ipuzString(object["cell"])
+		c.Value = ipuzString(object["value"])
+		c.Style, _ = object["style"].(map[string]any)
+		return nil
+	}
+	c.Omitted = raw == nil
+	c.Label = ipuzString(raw)
+	return nil
+}
+
+// ipuzValue is a cell of the solution or saved grids, given as a string or an object with a value.
+type ipuzValue struct {
+	Value string
+}
+
+func (v ipuzValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+func (v *ipuzValue) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if object, ok := raw.(map[string]any); ok {
+		raw = object["value"]
+	}
+	v.Value = ipuzString(raw)
+	return nil
+}
+
+// ipuzClue is a clue given as an array of the number and clue, or an object.
+type ipuzClue struct {
+	Number      any     `json:"number"`
+	Label       string  `json:"label,omitempty"`
+	Clue        string  `json:"clue"`
+	Enumeration string  `json:"enumeration,omitempty"`
+	Cells       [][]int `json:"cells,omitempty"`
+}
+
+func (c *ipuzClue) UnmarshalJSON(data []byte) error {
+	var array []any
+	if err := json.Unmarshal(data, &array); err == nil {
+		if len(array) < 2 {
+			return fmt.Errorf("clue array should contain the number and clue: %s", data)
+		}
+		c.Number, c.Clue = array[0], ipuzString(array[1])
+		return nil
+	}
+	type clue ipuzClue
+	return json.Unmarshal(data, (*clue)(c))
+}
+
+// ipuzString converts the scalar JSON values used for cells and labels to a string.
+func ipuzString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// MarshalIPUZ encodes the crossword as an ipuz crossword. Empty cells are written as blocks and
+// cells outside the Mask are omitted. Each clue lists its cells so the words do not need to follow
+// the conventional numbering, but since a cell only has one label, words starting in the same cell
+// share the number of the first of them. Character hints are written as given values, rebus
+// answers and circled cells are kept, and the cells of solved words are written to the saved grid.
+func MarshalIPUZ(cw *Crossword) ([]byte, error) {
+	width, height := cw.Grid.Width(), cw.Grid.Height()
+	doc := ipuz{
+		Version:    ipuzVersion,
+		Kind:       []string{ipuzKind},
+		Title:      cw.Metadata.Title,
+		Author:     cw.Metadata.Author,
+		Copyright:  cw.Metadata.Copyright,
+		Notes:      cw.Metadata.Notes,
+		Dimensions: ipuzDimensions{Width: width, Height: height},
+		Block:      ipuzBlock,
+		Empty:      json.RawMessage(ipuzEmpty),
+		Puzzle:     make([][]ipuzCell, height),
+		Solution:   make([][]*ipuzValue, height),
+		Clues:      map[string][]ipuzClue{},
+	}
+
+	blocks := cw.blocks()
+	saved := make([][]*ipuzValue, height)
+	hasSaved := false
+	for y := range height {
+		doc.Puzzle[y] = make([]ipuzCell, width)
+		doc.Solution[y] = make([]*ipuzValue, width)
+		saved[y] = make([]*ipuzValue, width)
+		for x, cell := range cw.Grid[y] {
+			switch {
+			case !cw.Mask.Allowed(x, y):
+				doc.Puzzle[y][x] = ipuzCell{Omitted: true}
+			case blocks[y][x]:
+				doc.Puzzle[y][x] = ipuzCell{Label: ipuzBlock}
+				doc.Solution[y][x] = &ipuzValue{Value: ipuzBlock}
+				saved[y][x] = &ipuzValue{Value: ipuzBlock}
+			default:
+				doc.Puzzle[y][x] = ipuzCell{Label: ipuzEmpty}
+				if cell.Circled {
+					doc.Puzzle[y][x].Style = map[string]any{ipuzShapeBG: ipuzCircle}
+				}
+				doc.Solution[y][x] = &ipuzValue{Value: cw.cellText(x, y, false)}
+				saved[y][x] = &ipuzValue{}
+			}
+		}
+	}
+
+	starts := map[[2]int]ipuzClue{}
+	for _, pl := range cw.Words {
+		clue := ipuzClue{Number: pl.ID, Clue: pl.Word.Clue, Enumeration: pl.Word.LetterCountStr()}
+		if pl.Word.Label != nil {
+			clue.Label = *pl.Word.Label
+		}
+		if start, ok := starts[[2]int{pl.X, pl.Y}]; ok {
+			clue.Number, clue.Label = start.Number, start.Label
+		} else {
+			starts[[2]int{pl.X, pl.Y}] = clue
+			doc.Puzzle[pl.Y][pl.X].Label = strconv.Itoa(pl.ID)
+			if pl.Word.Label != nil {
+				doc.Puzzle[pl.Y][pl.X].Label = *pl.Word.Label
+			}
+		}
+		for charIdx := range pl.Word.Len() {
+			x, y := pl.cell(charIdx)
+			clue.Cells = append(clue.Cells, []int{x + 1, y + 1})
+			if slices.Contains(pl.Word.CharacterHints, charIdx) {
+				doc.Puzzle[y][x].Value = cw.cellText(x, y, false)
+			}
+			if pl.Solved {
+				saved[y][x].Value = cw.cellText(x, y, false)
+				hasSaved = true
+			}
+		}
+		direction := ipuzAcross
+		if pl.Vertical {
+			direction = ipuzDown
+		}
+		doc.Clues[direction] = append(doc.Clues[direction], clue)
+	}
+	if hasSaved {
+		doc.Saved = saved
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalIPUZ decodes an ipuz crossword, which must include the solution. Clues without a list
+// of cells are placed using the numbers in the puzzle grid. Omitted cells are outside the Mask and,
+// along with blocks, are empty in the Grid. Given values become character hints and the words
+// completed in the saved grid are marked as solved.
+func UnmarshalIPUZ(data []byte) (*Crossword, error) {
+	doc := ipuz{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIPUZ, err)
+	}
+	if !slices.ContainsFunc(doc.Kind, func(kind string) bool { return strings.HasPrefix(kind, ipuzKindMatch) }) {
+		return nil, fmt.Errorf("%w: kind must be %s", ErrInvalidIPUZ, ipuzKind)
+	}
+	width, height := doc.Dimensions.Width, doc.Dimensions.Height
+	if !ipuzGridSize(doc.Puzzle, width, height) {
+		return nil, fmt.Errorf("%w: puzzle should be %dx%d", ErrInvalidIPUZ, width, height)
+	}
+	if !ipuzGridSize(doc.Solution, width, height) {
+		return nil, fmt.Errorf("%w: solution should be %dx%d", ErrInvalidIPUZ, width, height)
+	}
+	if doc.Saved != nil && !ipuzGridSize(doc.Saved, width, height) {
+		return nil, fmt.Errorf("%w: saved should be %dx%d", ErrInvalidIPUZ, width, height)
+	}
+	block := ipuzBlock
+	if doc.Block != "" {
+		block = doc.Block
+	}
+
+	cw := &Crossword{
+		Grid: NewRectGrid(width, height),
+		Metadata: Metadata{
+			Title:     doc.Title,
+			Author:    doc.Author,
+			Copyright: doc.Copyright,
+			Notes:     doc.Notes,
+		},
+	}
+	blocks := make([][]bool, height)
+	for y := range height {
+		blocks[y] = make([]bool, width)
+		for x, cell := range doc.Puzzle[y] {
+			if cell.Omitted {
+				if cw.Mask == nil {
+					cw.Mask = make(Mask, height)
+					for k := range cw.Mask {
+						cw.Mask[k] = slices.Repeat([]bool{true}, width)
+					}
+				}
+				cw.Mask[y][x] = false
+			}
+			solution := doc.Solution[y][x]
+			if cell.Omitted || cell.Label == block || solution == nil || solution.Value == "" || solution.Value == block {
+				blocks[y][x] = true
+				continue
+			}
+			chars := []rune(solution.Value)
+			cw.Grid[y][x] = Cell{Char: chars[0], Circled: cell.Style[ipuzShapeBG] == ipuzCircle}
+			if len(chars) > 1 {
+				cw.Grid[y][x].Rebus = solution.Value
+			}
+		}
+	}
+
+	for _, direction := range slices.Sorted(maps.Keys(doc.Clues)) {
+		// directions may have a heading to display e.g. "Across:Horizontal"
+		name, _, _ := strings.Cut(direction, ":")
+		if name != ipuzAcross && name != ipuzDown {
+			continue
+		}
+		for _, clue := range doc.Clues[direction] {
+			pl, err := ipuzPlacement(doc.Puzzle, blocks, clue, name == ipuzDown)
+			if err != nil {
+				return nil, err
+			}
+			cw.Words = append(cw.Words, pl)
+		}
+	}
+	for k, pl := range cw.Words {
+		chars := make([]rune, pl.Word.Len())
+		for charIdx := range chars {
+			x, y := pl.cell(charIdx)
+			chars[charIdx] = cw.Grid[y][x].Char
+			cw.Grid[y][x].CharIdx = charIdx
+		}
+		cw.Words[k].Word.Word = string(chars)
+	}
+
+	cw.markRevealed(func(x, y int) bool {
+		if doc.Puzzle[y][x].Value != "" {
+			return true
+		}
+		return doc.Saved != nil && doc.Saved[y][x] != nil && doc.Saved[y][x].Value != "" && doc.Saved[y][x].Value != block
+	})
+	return cw, nil
+}
+
+func ipuzGridSize[T any](grid [][]T, width, height int) bool {
+	if len(grid) != height {
+		return false
+	}
+	for _, row := range grid {
+		if len(row) != width {
+			return false
+		}
+	}
+	return true
+}
+
+// ipuzPlacement creates a placement for the clue, with the word's characters left as placeholders
+// to be filled from the grid.
+func ipuzPlacement(puzzle [][]ipuzCell, blocks [][]bool, clue ipuzClue, vertical bool) (Placement, error) {
+	label := ipuzString(clue.Number)
+	pl := Placement{Vertical: vertical, Word: Word{Clue: clue.Clue}}
+	if id, err := strconv.Atoi(label); err == nil {
+		pl.ID = id
+	}
+	if clue.Label != "" {
+		pl.Word.Label = &clue.Label
+	} else if label != "" && label != strconv.Itoa(pl.ID) {
+		pl.Word.Label = &label
+	}
+
+	length := 0
+	if len(clue.Cells) > 0 {
+		for k, cell := range clue.Cells {
+			if len(cell) != 2 {
+				return pl, fmt.Errorf("%w: clue %s has an invalid cell %v", ErrInvalidIPUZ, label, cell)
+			}
+			if k == 0 {
+				pl.X, pl.Y = cell[0]-1, cell[1]-1
+			}
+			if x, y := pl.cell(k); cell[0]-1 != x || cell[1]-1 != y {
+				return pl, fmt.Errorf("%w: the cells of clue %s are not in a line", ErrInvalidIPUZ, label)
+			}
+		}
+		length = len(clue.Cells)
+	} else {
+		found := false
+		for y := range puzzle {
+			for x, cell := range puzzle[y] {
+				if cell.Label == label && !blocks[y][x] {
+					pl.X, pl.Y, found = x, y, true
+				}
+			}
+		}
+		if !found {
+			return pl, fmt.Errorf("%w: clue %s is not in the puzzle", ErrInvalidIPUZ, label)
+		}
+		for {
+			x, y := pl.cell(length)
+			if y >= len(blocks) || x >= len(blocks[y]) || blocks[y][x] {
+				break
+			}
+			length++
+		}
+	}
+	for charIdx := range length {
+		x, y := pl.cell(charIdx)
+		if y < 0 || y >= len(blocks) || x < 0 || x >= len(blocks[y]) || blocks[y][x] {
+			return pl, fmt.Errorf("%w: clue %s includes a cell outside the grid or a block", ErrInvalidIPUZ, label)
+		}
+	}
+
+	// the word is filled from the grid once every clue has been placed
+	pl.Word.Word = strings.Repeat("?", length)
+	pl.Word.LettersCounts = parseEnumeration(clue.Enumeration, length)
+	return pl, nil
+}
+
+// parseEnumeration parses the letter counts of an enumeration such as "(3,4)" or "3-4". The
+// whole word is one count if the enumeration is missing or does not sum to the length.
+func parseEnumeration(enumeration string, length int) []int {
+	var counts []int
+	total := 0
+	for _, part := range enumerationSeparator.Split(strings.Trim(enumeration, " ()"), -1) {
+		if n, err := strconv.Atoi(part); err == nil {
+			counts = append(counts, n)
+			total += n
+		}
+	}
+	if total != length {
+		return []int{length}
+	}
+	return counts
+}
@@ -0,0 +1,126 @@
+package crossword
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalIPUZ(t *testing.T) {
+	t.Run("free-form", func(t *testing.T) {
+		cw := testCrossword(t)
+		label := "1a"
+		cw.Words[0].Word.Label = &label
+		cw.Words[0].Solved = true
+		cw.Words[1].Word.CharacterHints = []int{1}
+		cw.Words[1].Word.LettersCounts = []int{2, cw.Words[1].Word.Len() - 2}
+		cw.Grid[0][0].Circled = true
+		cw.Mask = MaskFromString(strings.Repeat("#", cw.Grid.Width()-1) + "\n" + strings.Repeat(strings.Repeat("#", cw.Grid.Width())+"\n", cw.Grid.Height()-1))
+		cw.Metadata = Metadata{Title: "Title", Author: "Author"}
+
+		data, err := MarshalIPUZ(cw)
+		require.NoError(t, err)
+		read, err := UnmarshalIPUZ(data)
+		require.NoError(t, err)
+
+		require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+		require.ElementsMatch(t, placedClues(cw), placedClues(read))
+		require.Equal(t, cw.Metadata, read.Metadata)
+		require.True(t, read.Grid[0][0].Circled)
+		require.Equal(t, cw.Mask, read.Mask)
+
+		across := read.Words[0]
+		require.Equal(t, "A1a", across.ClueID())
+		require.True(t, across.Solved)
+		for _, pl := range read.Words {
+			if pl.Word.Word == cw.Words[1].Word.Word {
+				require.Equal(t, []int{1}, pl.Word.CharacterHints)
+				require.Equal(t, cw.Words[1].Word.LettersCounts, pl.Word.LettersCounts)
+				require.Equal(t, cw.Words[1].ID, pl.ID)
+			}
+		}
+	})
+	t.Run("words starting in the same cell", func(t *testing.T) {
+		cw := &Crossword{Grid: NewRectGrid(3, 3), Words: []Placement{
+			{ID: 1, Word: Word{Word: "CAT", Clue: "cat clue"}},
+			{ID: 2, Word: Word{Word: "COW", Clue: "cow clue"}, Vertical: true},
+		}}
+		for _, pl := range cw.Words {
+			for charIdx, char := range pl.Word.chars() {
+				x, y := pl.cell(charIdx)
+				cw.Grid[y][x] = Cell{Char: char, CharIdx: charIdx}
+			}
+		}
+
+		data, err := MarshalIPUZ(cw)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"puzzle":[[1,0,0],[0,"#","#"],[0,"#","#"]]`)
+		read, err := UnmarshalIPUZ(data)
+		require.NoError(t, err)
+		require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+		require.ElementsMatch(t, placedClues(cw), placedClues(read))
+
+		var ids []string
+		for _, pl := range read.Words {
+			ids = append(ids, pl.ClueID())
+		}
+		require.ElementsMatch(t, []string{"A1", "D1"}, ids)
+	})
+	t.Run("blocked", func(t *testing.T) {
+		template, err := ParseTemplate("...\n...\nW..")
+		require.NoError(t, err)
+		var dictionary []DictionaryEntry
+		for _, w := range []string{"bat", "ore", "wed", "bow", "are", "ted"} {
+			dictionary = append(dictionary, DictionaryEntry{Word: Word{Word: w, Clue: w + " clue"}})
+		}
+		cw, err := Autofill(context.Background(), template, dictionary)
+		require.NoError(t, err)
+		cw.Grid[0][1].Rebus = "ALL"
+
+		data, err := MarshalIPUZ(cw)
+		require.NoError(t, err)
+		read, err := UnmarshalIPUZ(data)
+		require.NoError(t, err)
+		require.Nil(t, read.Mask)
+		require.Equal(t, "ALL", read.Grid[0][1].Rebus)
+		require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+	})
+}
+
+func TestUnmarshalIPUZ(t *testing.T) {
+	t.Run("clues placed by number", func(t *testing.T) {
+		cw, err := UnmarshalIPUZ([]byte(`{
+			"version": "http://ipuz.org/v2",
+			"kind": ["http://ipuz.org/crossword#1"],
+			"dimensions": {"width": 3, "height": 3},
+			"puzzle": [[1, 2, 3], [4, 0, 0], [5, 0, {"cell": 0, "value": "D"}]],
+			"solution": [["B", "A", "T"], ["O", "R", "E"], ["W", "E", "D"]],
+			"saved": [["B", "A", "T"], ["", "", ""], ["", "", ""]],
+			"clues": {
+				"Across": [[1, "Flying mammal"], [4, "Metal source"], {"number": 5, "clue": "Marry", "enumeration": "3"}],
+				"Down:Vertical": [[1, "Curtsy"], [2, "Exist"], [3, "Bear"]]
+			}
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, "BAT\nORE\nWED\n", RenderText(cw, WithAllSolved(true)))
+		require.Len(t, cw.Words, 6)
+		require.Equal(t, "D3", cw.Words[5].ClueID())
+		require.Equal(t, "TED", cw.Words[5].Word.Word)
+		require.Equal(t, "Bear", cw.Words[5].Word.Clue)
+		require.True(t, cw.Words[0].Solved)
+		require.Equal(t, []int{2}, cw.Words[2].Word.CharacterHints)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"not json":         `{`,
+			"wrong kind":       `{"kind": ["http://ipuz.org/sudoku#1"]}`,
+			"wrong dimensions": `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 1}, "puzzle": [[0]], "solution": [["A"]]}`,
+			"missing clue":     `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 1}, "puzzle": [[1, 0]], "solution": [["A", "B"]], "clues": {"Across": [[2, "clue"]]}}`,
+		} {
+			_, err := UnmarshalIPUZ([]byte(data))
+			require.ErrorIs(t, err, ErrInvalidIPUZ, name)
+		}
+	})
+}
@@ -0,0 +1,360 @@
+package crossword
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	jpzAppletNamespace = "http://crossword.info/xml/crossword-compiler-applet"
+	jpzPuzzleNamespace = "http://crossword.info/xml/rectangular-puzzle"
+	jpzBlock           = "block"
+	jpzVoid            = "void"
+	jpzCircle          = "circle"
+)
+
+// ErrInvalidJPZ is returned by ReadJPZ when the data is not a valid Crossword Compiler puzzle.
+var ErrInvalidJPZ = errors.New("invalid jpz crossword")
+
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+type jpz struct {
+	XMLName xml.Name
+	Xmlns   string    `xml:"xmlns,attr,omitempty"`
+	Puzzle  jpzPuzzle `xml:"rectangular-puzzle"`
+}
+
+type jpzPuzzle struct {
+	Xmlns     string       `xml:"xmlns,attr,omitempty"`
+	Metadata  jpzMetadata  `xml:"metadata"`
+	Crossword jpzCrossword `xml:"crossword"`
+}
+
+type jpzMetadata struct {
+	Title       string `xml:"title,omitempty"`
+	Creator     string `xml:"creator,omitempty"`
+	Copyright   string `xml:"copyright,omitempty"`
+	Description string `xml:"description,omitempty"`
+}
+
+type jpzCrossword struct {
+	Grid  jpzGrid    `xml:"grid"`
+	Words []jpzWord  `xml:"word"`
+	Clues []jpzClues `xml:"clues"`
+}
+
+type jpzGrid struct {
+	Width  int       `xml:"width,attr"`
+	Height int       `xml:"height,attr"`
+	Cells  []jpzCell `xml:"cell"`
+}
+
+type jpzCell struct {
+	X               int    `xml:"x,attr"`
+	Y               int    `xml:"y,attr"`
+	Type            string `xml:"type,attr,omitempty"`
+	Solution        string `xml:"solution,attr,omitempty"`
+	Number          string `xml:"number,attr,omitempty"`
+	Hint            bool   `xml:"hint,attr,omitempty"`
+	SolveState      string `xml:"solve-state,attr,omitempty"`
+	BackgroundShape string `xml:"background-shape,attr,omitempty"`
+}
+
+// jpzWord lists the cells of a word as ranges of x and y coordinates e.g. x="1-5" y="3", or as
+// individual cells.
+type jpzWord struct {
+	ID    string    `xml:"id,attr"`
+	X     string    `xml:"x,attr,omitempty"`
+	Y     string    `xml:"y,attr,omitempty"`
+	Cells []jpzWord `xml:"cells"`
+}
+
+type jpzClues struct {
+	Ordering string    `xml:"ordering,attr,omitempty"`
+	Title    jpzInner  `xml:"title"`
+	Clues    []jpzClue `xml:"clue"`
+}
+
+type jpzClue struct {
+	Word   string `xml:"word,attr"`
+	Number string `xml:"number,attr,omitempty"`
+	Format string `xml:"format,attr,omitempty"`
+	Inner  string `xml:",innerxml"`
+}
+
+type jpzInner struct {
+	XML string `xml:",innerxml"`
+}
+
+// WriteJPZ writes the crossword as a Crossword Compiler XML (.jpz) puzzle. Empty cells are written as
+// blocks and cells outside the Mask as voids. Each word lists its cells and each clue includes the
+// enumeration from Word.LetterCountStr. Character hints and the cells of solved words are written
+// as hints and the solver's entries respectively.
+func WriteJPZ(w io.Writer, cw *Crossword) error {
+	width, height := cw.Grid.Width(), cw.Grid.Height()
+	doc := jpz{
+		XMLName: xml.Name{Local: "crossword-compiler-applet"},
+		Xmlns:   jpzAppletNamespace,
+		Puzzle: jpzPuzzle{
+			Xmlns: jpzPuzzleNamespace,
+			Metadata: jpzMetadata{
+				Title:       cw.Metadata.Title,
+				Creator:     cw.Metadata.Author,
+				Copyright:   cw.Metadata.Copyright,
+				Description: cw.Metadata.Notes,
+			},
+			Crossword: jpzCrossword{Grid: jpzGrid{Width: width, Height: height}},
+		},
+	}
+
+	blocks := cw.blocks()
+	cells := make([][]jpzCell, height)
+	for y := range height {
+		cells[y] = make([]jpzCell, width)
+		for x, cell := range cw.Grid[y] {
+			cells[y][x] = jpzCell{X: x + 1, Y: y + 1}
+			switch {
+			case !cw.Mask.Allowed(x, y):
+				cells[y][x].Type = jpzVoid
+			case blocks[y][x]:
+				cells[y][x].Type = jpzBlock
+			default:
+				cells[y][x].Solution = cw.cellText(x, y, false)
+				if cell.Circled {
+					cells[y][x].BackgroundShape = jpzCircle
+				}
+			}
+		}
+	}
+
+	across := jpzClues{Ordering: "normal", Title: jpzInner{XML: "<b>Across</b>"}}
+	down := jpzClues{Ordering: "normal", Title: jpzInner{XML: "<b>Down</b>"}}
+	for k, pl := range cw.Words {
+		label := strconv.Itoa(pl.ID)
+		if pl.Word.Label != nil {
+			label = *pl.Word.Label
+		}
+		if cells[pl.Y][pl.X].Number == "" {
+			cells[pl.Y][pl.X].Number = label
+		}
+
+		word := jpzWord{ID: strconv.Itoa(k + 1)}
+		endX, endY := pl.end()
+		word.X, word.Y = jpzRange(pl.X, endX), jpzRange(pl.Y, endY)
+		doc.Puzzle.Crossword.Words = append(doc.Puzzle.Crossword.Words, word)
+
+		for charIdx := range pl.Word.Len() {
+			x, y := pl.cell(charIdx)
+			if slices.Contains(pl.Word.CharacterHints, charIdx) {
+				cells[y][x].Hint = true
+			}
+			if pl.Solved {
+				cells[y][x].SolveState = cells[y][x].Solution
+			}
+		}
+
+		clue := jpzClue{Word: word.ID, Number: label, Format: pl.Word.LetterCountStr()}
+		clue.Inner = html.EscapeString(pl.Word.Clue)
+		if pl.Vertical {
+			down.Clues = append(down.Clues, clue)
+		} else {
+			across.Clues = append(across.Clues, clue)
+		}
+	}
+	for y := range cells {
+		doc.Puzzle.Crossword.Grid.Cells = append(doc.Puzzle.Crossword.Grid.Cells, cells[y]...)
+	}
+	doc.Puzzle.Crossword.Clues = []jpzClues{across, down}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode jpz: %w", err)
+	}
+	return nil
+}
+
+// jpzRange formats the 1-based range of coordinates from start to end.
+func jpzRange(start, end int) string {
+	if start == end {
+		return strconv.Itoa(start + 1)
+	}
+	return fmt.Sprintf("%d-%d", start+1, end+1)
+}
+
+// parseJPZRange parses a 1-based range of coordinates written by jpzRange.
+func parseJPZRange(s string) (start, end int, err error) {
+	first, last, isRange := strings.Cut(s, "-")
+	if start, err = strconv.Atoi(strings.TrimSpace(first)); err != nil {
+		return 0, 0, err
+	}
+	end = start
+	if isRange {
+		if end, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start - 1, end - 1, nil
+}
+
+// ReadJPZ reads a Crossword Compiler XML (.jpz) puzzle, which may be zip compressed. Each clue becomes
+// a word, with cells given as hints becoming character hints and the words completed in the solver's
+// entries marked as solved. Blocks and voids are empty cells and voids are outside the Mask.
+func ReadJPZ(r io.Reader) (*Crossword, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jpz: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if data, err = unzipJPZ(data); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := jpz{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJPZ, err)
+	}
+	grid := doc.Puzzle.Crossword.Grid
+	if grid.Width <= 0 || grid.Height <= 0 {
+		return nil, fmt.Errorf("%w: grid is empty", ErrInvalidJPZ)
+	}
+
+	cw := &Crossword{
+		Grid: NewRectGrid(grid.Width, grid.Height),
+		Metadata: Metadata{
+			Title:     doc.Puzzle.Metadata.Title,
+			Author:    doc.Puzzle.Metadata.Creator,
+			Copyright: doc.Puzzle.Metadata.Copyright,
+			Notes:     doc.Puzzle.Metadata.Description,
+		},
+	}
+	cells := make([][]*jpzCell, grid.Height)
+	for y := range cells {
+		cells[y] = make([]*jpzCell, grid.Width)
+	}
+	for k, cell := range grid.Cells {
+		x, y := cell.X-1, cell.Y-1
+		if x < 0 || y < 0 || x >= grid.Width || y >= grid.Height {
+			return nil, fmt.Errorf("%w: cell %d,%d is outside the grid", ErrInvalidJPZ, cell.X, cell.Y)
+		}
+		cells[y][x] = &grid.Cells[k]
+		switch cell.Type {
+		case jpzVoid:
+			if cw.Mask == nil {
+				cw.Mask = make(Mask, grid.Height)
+				for k := range cw.Mask {
+					cw.Mask[k] = slices.Repeat([]bool{true}, grid.Width)
+				}
+			}
+			cw.Mask[y][x] = false
+		case jpzBlock:
+		default:
+			if cell.Solution == "" {
+				continue
+			}
+			chars := []rune(cell.Solution)
+			cw.Grid[y][x] = Cell{Char: chars[0], Circled: cell.BackgroundShape == jpzCircle}
+			if len(chars) > 1 {
+				cw.Grid[y][x].Rebus = cell.Solution
+			}
+		}
+	}
+
+	words := map[string][]jpzWord{}
+	for _, word := range doc.Puzzle.Crossword.Words {
+		words[word.ID] = append([]jpzWord{word}, word.Cells...)
+	}
+	for _, clues := range doc.Puzzle.Crossword.Clues {
+		for _, clue := range clues.Clues {
+			ranges, ok := words[clue.Word]
+			if !ok {
+				return nil, fmt.Errorf("%w: clue %s refers to a missing word %s", ErrInvalidJPZ, clue.Number, clue.Word)
+			}
+			pl, err := cw.jpzPlacement(ranges, clue)
+			if err != nil {
+				return nil, err
+			}
+			cw.Words = append(cw.Words, pl)
+		}
+	}
+
+	cw.markRevealed(func(x, y int) bool {
+		return cells[y][x] != nil && (cells[y][x].Hint || cells[y][x].SolveState != "")
+	})
+	return cw, nil
+}
+
+// jpzPlacement creates a placement for the clue from the cells of its word.
+func (cw *Crossword) jpzPlacement(ranges []jpzWord, clue jpzClue) (Placement, error) {
+	var cells [][2]int
+	for _, r := range ranges {
+		if r.X == "" && r.Y == "" {
+			continue
+		}
+		startX, endX, errX := parseJPZRange(r.X)
+		startY, endY, errY := parseJPZRange(r.Y)
+		if errX != nil || errY != nil {
+			return Placement{}, fmt.Errorf("%w: word %s has invalid cells x=%q y=%q", ErrInvalidJPZ, r.ID, r.X, r.Y)
+		}
+		for y := startY; y <= endY; y++ {
+			for x := startX; x <= endX; x++ {
+				cells = append(cells, [2]int{x, y})
+			}
+		}
+	}
+	if len(cells) == 0 {
+		return Placement{}, fmt.Errorf("%w: word %s has no cells", ErrInvalidJPZ, clue.Word)
+	}
+
+	pl := Placement{X: cells[0][0], Y: cells[0][1], Vertical: len(cells) > 1 && cells[1][0] == cells[0][0]}
+	if id, err := strconv.Atoi(clue.Number); err == nil {
+		pl.ID = id
+	} else if clue.Number != "" {
+		pl.Word.Label = &clue.Number
+	}
+	chars := make([]rune, len(cells))
+	for charIdx, cell := range cells {
+		if x, y := pl.cell(charIdx); x != cell[0] || y != cell[1] {
+			return Placement{}, fmt.Errorf("%w: the cells of word %s are not in a line", ErrInvalidJPZ, clue.Word)
+		}
+		if cell[0] < 0 || cell[1] < 0 || cell[0] >= cw.Grid.Width() || cell[1] >= cw.Grid.Height() || cw.Grid[cell[1]][cell[0]].Empty() {
+			return Placement{}, fmt.Errorf("%w: word %s includes a cell outside the grid or without a solution", ErrInvalidJPZ, clue.Word)
+		}
+		chars[charIdx] = cw.Grid[cell[1]][cell[0]].Char
+		cw.Grid[cell[1]][cell[0]].CharIdx = charIdx
+	}
+	pl.Word.Word = string(chars)
+	pl.Word.Clue = strings.TrimSpace(html.UnescapeString(htmlTags.ReplaceAllString(clue.Inner, "")))
+	pl.Word.LettersCounts = parseEnumeration(clue.Format, len(cells))
+	return pl, nil
+}
+
+// unzipJPZ returns the first file in a zip compressed jpz.
+func unzipJPZ(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJPZ, err)
+	}
+	if len(archive.File) == 0 {
+		return nil, fmt.Errorf("%w: zip archive is empty", ErrInvalidJPZ)
+	}
+	f, err := archive.File[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJPZ, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
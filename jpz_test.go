@@ -0,0 +1,118 @@
+package crossword
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJPZ(t *testing.T) {
+	cw := testCrossword(t)
+	label := "1a"
+	cw.Words[0].Word.Label = &label
+	cw.Words[0].Word.Clue = "Fruit & veg"
+	cw.Words[0].Solved = true
+	cw.Words[1].Word.CharacterHints = []int{1}
+	cw.Words[1].Word.LettersCounts = []int{2, cw.Words[1].Word.Len() - 2}
+	cw.Grid[0][0].Circled = true
+	cw.Grid[0][1].Rebus = "PP"
+	cw.Mask = MaskFromString(strings.Repeat("#", cw.Grid.Width()-1) + "\n" + strings.Repeat(strings.Repeat("#", cw.Grid.Width())+"\n", cw.Grid.Height()-1))
+	cw.Metadata = Metadata{Title: "Title", Author: "Author", Copyright: "Copyright", Notes: "Notes"}
+
+	buff := &bytes.Buffer{}
+	require.NoError(t, WriteJPZ(buff, cw))
+	require.Contains(t, buff.String(), `format="2,`)
+	require.Contains(t, buff.String(), `x="1-5" y="1"`)
+
+	read, err := ReadJPZ(buff)
+	require.NoError(t, err)
+	require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+	require.ElementsMatch(t, placedClues(cw), placedClues(read))
+	require.Equal(t, cw.Metadata, read.Metadata)
+	require.Equal(t, cw.Mask, read.Mask)
+	require.True(t, read.Grid[0][0].Circled)
+	require.Equal(t, "PP", read.Grid[0][1].Rebus)
+
+	require.Equal(t, "A1a", read.Words[0].ClueID())
+	require.Equal(t, "Fruit & veg", read.Words[0].Word.Clue)
+	require.True(t, read.Words[0].Solved)
+	for _, pl := range read.Words {
+		if pl.Word.Word == cw.Words[1].Word.Word {
+			require.Equal(t, []int{1}, pl.Word.CharacterHints)
+			require.Equal(t, cw.Words[1].Word.LettersCounts, pl.Word.LettersCounts)
+			require.Equal(t, cw.Words[1].ID, pl.ID)
+		}
+	}
+}
+
+func TestReadJPZ(t *testing.T) {
+	puzzle := `<?xml version="1.0" encoding="UTF-8"?>
+<crossword-compiler xmlns="http://crossword.info/xml/crossword-compiler">
+  <rectangular-puzzle xmlns="http://crossword.info/xml/rectangular-puzzle" alphabet="ABCDEFGHIJKLMNOPQRSTUVWXYZ">
+    <metadata><title>Tiny</title></metadata>
+    <crossword>
+      <grid width="3" height="2">
+        <grid-look numbering-scheme="normal"/>
+        <cell x="1" y="1" solution="B" number="1"/>
+        <cell x="2" y="1" solution="A"/>
+        <cell x="3" y="1" solution="T" hint="true"/>
+        <cell x="1" y="2" solution="O"/>
+        <cell x="2" y="2" type="block"/>
+        <cell x="3" y="2" type="block"/>
+      </grid>
+      <word id="1" x="1-3" y="1"/>
+      <word id="2"><cells x="1" y="1"/><cells x="1" y="2"/></word>
+      <clues ordering="normal">
+        <title><b>Across</b></title>
+        <clue word="1" number="1" format="3">Flying <i>mammal</i></clue>
+      </clues>
+      <clues ordering="normal">
+        <title><b>Down</b></title>
+        <clue word="2" number="1" format="2">Exclamation</clue>
+      </clues>
+    </crossword>
+  </rectangular-puzzle>
+</crossword-compiler>`
+
+	t.Run("xml", func(t *testing.T) {
+		cw, err := ReadJPZ(strings.NewReader(puzzle))
+		require.NoError(t, err)
+		require.Equal(t, "BAT\nO##\n", RenderText(cw, WithAllSolved(true)))
+		require.Equal(t, "Tiny", cw.Metadata.Title)
+		require.Len(t, cw.Words, 2)
+		require.Equal(t, "A1", cw.Words[0].ClueID())
+		require.Equal(t, "Flying mammal", cw.Words[0].Word.Clue)
+		require.Equal(t, []int{2}, cw.Words[0].Word.CharacterHints)
+		require.Equal(t, "D1", cw.Words[1].ClueID())
+		require.Equal(t, "BO", cw.Words[1].Word.Word)
+		require.Nil(t, cw.Mask)
+	})
+	t.Run("zip", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		archive := zip.NewWriter(buff)
+		f, err := archive.Create("tiny.xml")
+		require.NoError(t, err)
+		_, err = f.Write([]byte(puzzle))
+		require.NoError(t, err)
+		require.NoError(t, archive.Close())
+
+		cw, err := ReadJPZ(buff)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 2)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"not xml":        `<crossword-compiler`,
+			"empty grid":     `<crossword-compiler><rectangular-puzzle><crossword><grid width="0" height="0"/></crossword></rectangular-puzzle></crossword-compiler>`,
+			"missing word":   `<crossword-compiler><rectangular-puzzle><crossword><grid width="1" height="1"><cell x="1" y="1" solution="A"/></grid><clues><clue word="1" number="1">clue</clue></clues></crossword></rectangular-puzzle></crossword-compiler>`,
+			"cell outside":   `<crossword-compiler><rectangular-puzzle><crossword><grid width="1" height="1"><cell x="2" y="1" solution="A"/></grid></crossword></rectangular-puzzle></crossword-compiler>`,
+			"word on blocks": `<crossword-compiler><rectangular-puzzle><crossword><grid width="2" height="1"><cell x="1" y="1" solution="A"/><cell x="2" y="1" type="block"/></grid><word id="1" x="1-2" y="1"/><clues><clue word="1" number="1">clue</clue></clues></crossword></rectangular-puzzle></crossword-compiler>`,
+		} {
+			_, err := ReadJPZ(strings.NewReader(data))
+			require.ErrorIs(t, err, ErrInvalidJPZ, name)
+		}
+	})
+}
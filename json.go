@@ -0,0 +1,232 @@
+package crossword
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// crosswordJSONVersion is the version of the JSON format written by Crossword.MarshalJSON.
+const crosswordJSONVersion = 1
+
+// ErrUnsupportedVersion is returned when decoding JSON written by a newer version of the package.
+var ErrUnsupportedVersion = errors.New("unsupported crossword version")
+
+type crosswordJSON struct {
+	Version    int             `json:"version"`
+	Width      int             `json:"width"`
+	Height     int             `json:"height"`
+	Words      []placementJSON `json:"words"`
+	Cells      []cellJSON      `json:"cells,omitempty"`
+	Mask       []string        `json:"mask,omitempty"`
+	Metadata   *metadataJSON   `json:"metadata,omitempty"`
+	TotalScore int             `json:"totalScore,omitempty"`
+	Seed       *uint64         `json:"seed,omitempty"`
+}
+
+type placementJSON struct {
+	ID             int     `json:"id"`
+	X              int     `json:"x"`
+	Y              int     `json:"y"`
+	Vertical       bool    `json:"vertical,omitempty"`
+	Solved         bool    `json:"solved,omitempty"`
+	Word           string  `json:"word"`
+	Clue           string  `json:"clue,omitempty"`
+	Label          *string `json:"label,omitempty"`
+	LettersCounts  []int   `json:"lettersCounts,omitempty"`
+	CharacterHints []int   `json:"characterHints,omitempty"`
+	Original       string  `json:"original,omitempty"`
+	Required       bool    `json:"required,omitempty"`
+	Priority       int     `json:"priority,omitempty"`
+}
+
+// cellJSON holds the properties of a cell which cannot be rebuilt from the words.
+type cellJSON struct {
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Circled bool   `json:"circled,omitempty"`
+	Rebus   string `json:"rebus,omitempty"`
+}
+
+type metadataJSON struct {
+	Title     string `json:"title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Copyright string `json:"copyright,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+// legacyCrossword decodes the JSON written before the format was versioned i.e. the default
+// encoding of every field of the Crossword.
+type legacyCrossword Crossword
+
+// MarshalJSON encodes the crossword in a versioned format holding the grid size, the placed words
+// with their clues and the metadata. The Grid is rebuilt from the words when it's decoded. The
+// Report is not included.
+func (cw Crossword) MarshalJSON() ([]byte, error) {
+	doc := crosswordJSON{
+		Version:    crosswordJSONVersion,
+		Width:      cw.Grid.Width(),
+		Height:     cw.Grid.Height(),
+		Words:      make([]placementJSON, len(cw.Words)),
+		TotalScore: cw.TotalScore,
+		Seed:       cw.Seed,
+	}
+	for k, pl := range cw.Words {
+		doc.Words[k] = placementJSON{
+			ID:             pl.ID,
+			X:              pl.X,
+			Y:              pl.Y,
+			Vertical:       pl.Vertical,
+			Solved:         pl.Solved,
+			Word:           pl.Word.Word,
+			Clue:           pl.Word.Clue,
+			Label:          pl.Word.Label,
+			LettersCounts:  pl.Word.LettersCounts,
+			CharacterHints: pl.Word.CharacterHints,
+			Original:       pl.Word.Original,
+			Required:       pl.Word.Required,
+			Priority:       pl.Word.Priority,
+		}
+	}
+	for y := range cw.Grid {
+		for x, cell := range cw.Grid[y] {
+			if cell.Circled || cell.Rebus != "" {
+				doc.Cells = append(doc.Cells, cellJSON{X: x, Y: y, Circled: cell.Circled, Rebus: cell.Rebus})
+			}
+		}
+	}
+	if cw.Mask != nil {
+		doc.Mask = make([]string, doc.Height)
+		for y := range doc.Height {
+			row := strings.Builder{}
+			for x := range doc.Width {
+				if cw.Mask.Allowed(x, y) {
+					row.WriteByte('#')
+				} else {
+					row.WriteByte('.')
+				}
+			}
+			doc.Mask[y] = row.String()
+		}
+	}
+	if cw.Metadata != (Metadata{}) {
+		doc.Metadata = &metadataJSON{
+			Title:     cw.Metadata.Title,
+			Author:    cw.Metadata.Author,
+			Copyright: cw.Metadata.Copyright,
+			Notes:     cw.Metadata.Notes,
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes a crossword written by MarshalJSON, rebuilding the Grid from the words.
+// JSON without a version is decoded as the default encoding of the Crossword used by earlier
+// versions of the package.
+func (cw *Crossword) UnmarshalJSON(data []byte) error {
+	version := struct {
+		Version *int `json:"version"`
+	}{}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return err
+	}
+	if version.Version == nil {
+		legacy := legacyCrossword{}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*cw = Crossword(legacy)
+		return nil
+	}
+	if *version.Version != crosswordJSONVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, *version.Version)
+	}
+
+	doc := crosswordJSON{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Width < 0 || doc.Height < 0 {
+		return fmt.Errorf("invalid grid size %dx%d", doc.Width, doc.Height)
+	}
+	decoded := Crossword{
+		Grid:       NewRectGrid(doc.Width, doc.Height),
+		Words:      make([]Placement, len(doc.Words)),
+		TotalScore: doc.TotalScore,
+		Seed:       doc.Seed,
+	}
+	for k, pl := range doc.Words {
+		decoded.Words[k] = Placement{
+			ID: pl.ID,
+			Word: Word{
+				Word:           pl.Word,
+				Clue:           pl.Clue,
+				Label:          pl.Label,
+				LettersCounts:  pl.LettersCounts,
+				CharacterHints: pl.CharacterHints,
+				Original:       pl.Original,
+				Required:       pl.Required,
+				Priority:       pl.Priority,
+			},
+			X:        pl.X,
+			Y:        pl.Y,
+			Vertical: pl.Vertical,
+			Solved:   pl.Solved,
+		}
+		if err := decoded.rebuildPlacement(decoded.Words[k]); err != nil {
+			return err
+		}
+	}
+	for _, pl := range decoded.Words {
+		for _, charIdx := range pl.Word.CharacterHints {
+			if charIdx >= 0 && charIdx < pl.Word.Len() {
+				x, y := pl.cell(charIdx)
+				decoded.Grid[y][x].CharIdx = charIdx
+			}
+		}
+	}
+	for _, cell := range doc.Cells {
+		if cell.X < 0 || cell.Y < 0 || cell.X >= doc.Width || cell.Y >= doc.Height {
+			return fmt.Errorf("cell %d,%d is outside the grid", cell.X, cell.Y)
+		}
+		decoded.Grid[cell.Y][cell.X].Circled = cell.Circled
+		decoded.Grid[cell.Y][cell.X].Rebus = cell.Rebus
+	}
+	if doc.Mask != nil {
+		decoded.Mask = make(Mask, doc.Height)
+		for y := range doc.Height {
+			decoded.Mask[y] = make([]bool, doc.Width)
+			if y < len(doc.Mask) {
+				for x, char := range []rune(doc.Mask[y]) {
+					if x < doc.Width {
+						decoded.Mask[y][x] = char == '#'
+					}
+				}
+			}
+		}
+	}
+	if doc.Metadata != nil {
+		decoded.Metadata = Metadata{
+			Title:     doc.Metadata.Title,
+			Author:    doc.Metadata.Author,
+			Copyright: doc.Metadata.Copyright,
+			Notes:     doc.Metadata.Notes,
+		}
+	}
+	*cw = decoded
+	return nil
+}
+
+// rebuildPlacement writes the characters of the placement into the grid.
+func (cw *Crossword) rebuildPlacement(pl Placement) error {
+	for charIdx, char := range pl.Word.chars() {
+		x, y := pl.cell(charIdx)
+		if x < 0 || y < 0 || x >= cw.Grid.Width() || y >= cw.Grid.Height() {
+			return fmt.Errorf("word %s is outside the grid", pl.ClueID())
+		}
+		cw.Grid[y][x].Char = char
+		cw.Grid[y][x].CharIdx = charIdx
+	}
+	return nil
+}
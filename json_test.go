@@ -0,0 +1,76 @@
+package crossword
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossword_MarshalJSON(t *testing.T) {
+	cw := testCrossword(t)
+	label := "1a"
+	cw.Words[0].Word.Label = &label
+	cw.Words[0].Solved = true
+	cw.Words[1].Word.CharacterHints = []int{1}
+	cw.Words[1].Word.LettersCounts = []int{2, cw.Words[1].Word.Len() - 2}
+	cw.Grid[0][0].Circled = true
+	cw.Grid[0][1].Rebus = "PP"
+	cw.Mask = MaskFromString(strings.Repeat("#", cw.Grid.Width()-1) + "\n" + strings.Repeat(strings.Repeat("#", cw.Grid.Width())+"\n", cw.Grid.Height()-1))
+	cw.Metadata = Metadata{Title: "Title", Author: "Author"}
+	seed := uint64(0)
+	cw.Seed = &seed
+
+	data, err := json.Marshal(cw)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"version":1`)
+	require.Contains(t, string(data), `"seed":0`)
+	require.NotContains(t, string(data), `"Grid"`)
+
+	read := &Crossword{}
+	require.NoError(t, json.Unmarshal(data, read))
+	require.Equal(t, cw.Words, read.Words)
+	require.Equal(t, cw.Metadata, read.Metadata)
+	require.Equal(t, cw.Mask, read.Mask)
+	require.Equal(t, cw.Seed, read.Seed)
+	require.Equal(t, cw.TotalScore, read.TotalScore)
+	require.Nil(t, read.Report)
+	require.True(t, read.Grid[0][0].Circled)
+	require.Equal(t, "PP", read.Grid[0][1].Rebus)
+	require.Equal(t, RenderText(cw), RenderText(read))
+	require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+}
+
+func TestCrossword_UnmarshalJSON(t *testing.T) {
+	t.Run("unversioned", func(t *testing.T) {
+		cw := testCrossword(t)
+		data, err := json.Marshal((*legacyCrossword)(cw))
+		require.NoError(t, err)
+
+		read := &Crossword{}
+		require.NoError(t, json.Unmarshal(data, read))
+		require.Equal(t, cw.Grid, read.Grid)
+		require.Equal(t, cw.Words, read.Words)
+		require.Equal(t, cw.Report, read.Report)
+
+		// the migrated crossword is written in the current format
+		data, err = json.Marshal(read)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"version":1`)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"newer version":  `{"version": 2}`,
+			"negative size":  `{"version": 1, "width": -1, "height": 1}`,
+			"word outside":   `{"version": 1, "width": 2, "height": 1, "words": [{"id": 1, "x": 0, "y": 0, "word": "ABC"}]}`,
+			"cell outside":   `{"version": 1, "width": 1, "height": 1, "cells": [{"x": 1, "y": 0, "circled": true}]}`,
+			"not an object":  `[]`,
+			"wrong type":     `{"version": "1"}`,
+			"malformed json": `{`,
+		} {
+			require.Error(t, json.Unmarshal([]byte(data), &Crossword{}), name)
+		}
+		require.ErrorIs(t, json.Unmarshal([]byte(`{"version": 2}`), &Crossword{}), ErrUnsupportedVersion)
+	})
+}
@@ -0,0 +1,102 @@
+package crossword
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithMask restricts the generated words to the allowed cells of the mask e.g. to fit the
+// crossword inside a shape. The mask should be the same size as the grid.
+func WithMask(mask Mask) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.mask = mask
+	}
+}
+
+// Mask marks the cells of a grid that words may use i.e. Mask[y][x] is true for allowed cells.
+// A nil mask allows every cell.
+type Mask [][]bool
+
+// MaskFromString creates a mask from rows of text where spaces and '.' are outside the
+// shape and any other character is allowed. Short rows are padded with disallowed cells.
+func MaskFromString(s string) Mask {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	mask := make(Mask, len(lines))
+	for y, line := range lines {
+		mask[y] = make([]bool, width)
+		for x, char := range []rune(line) {
+			mask[y][x] = char != ' ' && char != '.'
+		}
+	}
+	return mask
+}
+
+// MaskFromImage creates a mask of the given size by dividing the image into cells. A cell is
+// allowed if the pixel at its centre is at least half opaque.
+func MaskFromImage(img image.Image, width, height int) Mask {
+	bounds := img.Bounds()
+	mask := make(Mask, height)
+	for y := range height {
+		mask[y] = make([]bool, width)
+		for x := range width {
+			px := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*width)
+			py := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*height)
+			_, _, _, alpha := img.At(px, py).RGBA()
+			mask[y][x] = alpha >= 0x8000
+		}
+	}
+	return mask
+}
+
+// MaskFromPNG creates a mask of the given size from the alpha channel of a PNG. See MaskFromImage.
+func MaskFromPNG(r io.Reader, width, height int) (Mask, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PNG: %w", err)
+	}
+	return MaskFromImage(img, width, height), nil
+}
+
+// Allowed checks if words may use the cell. Cells outside a non-nil mask are not allowed.
+func (m Mask) Allowed(x, y int) bool {
+	if m == nil {
+		return true
+	}
+	return y >= 0 && y < len(m) && x >= 0 && x < len(m[y]) && m[y][x]
+}
+
+// allows checks every cell of the placement is allowed.
+func (m Mask) allows(pl Placement) bool {
+	if m == nil {
+		return true
+	}
+	for charIdx := range pl.Word.Len() {
+		if !m.Allowed(pl.cell(charIdx)) {
+			return false
+		}
+	}
+	return true
+}
+
+// translate moves the allowed cells by dx, dy into a new mask of the given size.
+func (m Mask) translate(dx, dy, width, height int) Mask {
+	if m == nil {
+		return nil
+	}
+	mask := make(Mask, height)
+	for y := range height {
+		mask[y] = make([]bool, width)
+		for x := range width {
+			mask[y][x] = m.Allowed(x-dx, y-dy)
+		}
+	}
+	return mask
+}
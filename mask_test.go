@@ -0,0 +1,62 @@
+package crossword
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskFromString(t *testing.T) {
+	mask := MaskFromString(" ##\n#.#\n#")
+	require.Equal(t, Mask{
+		{false, true, true},
+		{true, false, true},
+		{true, false, false},
+	}, mask)
+	require.False(t, mask.Allowed(3, 0))
+	require.True(t, Mask(nil).Allowed(3, 0))
+}
+
+func TestMaskFromPNG(t *testing.T) {
+	// the left half of the image is opaque
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := range 20 {
+		for x := range 20 {
+			img.Set(x, y, color.Black)
+		}
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, img))
+
+	mask, err := MaskFromPNG(buf, 4, 2)
+	require.NoError(t, err)
+	require.Equal(t, Mask{{true, true, false, false}, {true, true, false, false}}, mask)
+}
+
+func TestGenerator_Generate_masked(t *testing.T) {
+	mask := MaskFromString(`
+  #####
+  #####
+#######
+#######
+#######`)
+	cw := NewGenerator(7).Generate([]Word{{Word: "foo"}, {Word: "boat"}, {Word: "moat"}, {Word: "food"}, {Word: "mouse"}}, 3, WithMask(mask), WithSeed(1))
+	require.NotEmpty(t, cw.Words)
+	for _, pl := range cw.Words {
+		require.True(t, mask.allows(pl), "%s is outside the mask", pl.Word.Word)
+	}
+	require.Equal(t, mask, cw.Mask)
+
+	rendered := RenderText(cw)
+	require.Equal(t, "  ", rendered[:2])
+	require.Equal(t, "       \n", rendered[len(rendered)-8:], "rows outside the mask should be blank")
+
+	dc, err := RenderPNG(cw, 700, 700, WithBorder(0))
+	require.NoError(t, err)
+	_, _, _, alpha := dc.Image().At(10, 10).RGBA()
+	require.Zero(t, alpha, "cells outside the mask should be transparent")
+}
@@ -0,0 +1,107 @@
+package crossword
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer converts a word into the characters that will be placed in the grid. This allows
+// e.g. an accented letter to cross the unaccented version of the same letter in another word.
+// Normalizers should not alter spaces as they are used to find the letter counts of each word.
+type Normalizer interface {
+	Normalize(word string) string
+}
+
+// NormalizerFunc allows an ordinary function to be used as a Normalizer.
+type NormalizerFunc func(word string) string
+
+func (f NormalizerFunc) Normalize(word string) string {
+	return f(word)
+}
+
+// FoldingNormalizer removes diacritics from letters (e.g. É becomes E) after applying any
+// replacements.
+type FoldingNormalizer struct {
+	// Replacements maps upper-case letters to the string used in their place. Lower-case
+	// versions of the letters are replaced with the lower-case string.
+	Replacements map[rune]string
+
+	// Keep lists upper-case letters that should keep their diacritics (e.g. Ñ in Spanish).
+	Keep []rune
+}
+
+func (n FoldingNormalizer) Normalize(word string) string {
+	out := &strings.Builder{}
+	for _, r := range norm.NFC.String(word) {
+		upper := unicode.ToUpper(r)
+		if replacement, ok := n.Replacements[upper]; ok {
+			if unicode.IsLower(r) {
+				replacement = strings.ToLower(replacement)
+			}
+			out.WriteString(replacement)
+			continue
+		}
+		if strings.ContainsRune(string(n.Keep), upper) {
+			out.WriteRune(r)
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if !unicode.Is(unicode.Mn, d) {
+				out.WriteRune(d)
+			}
+		}
+	}
+	return out.String()
+}
+
+var (
+	// NormalizerFold removes all diacritics and replaces letters that do not decompose with
+	// their closest unaccented equivalents (e.g. ß becomes SS and Ø becomes O).
+	NormalizerFold = FoldingNormalizer{
+		Replacements: map[rune]string{
+			'ẞ': "SS",
+			'ß': "SS",
+			'Æ': "AE",
+			'Œ': "OE",
+			'Ø': "O",
+			'Ł': "L",
+			'Đ': "D",
+			'Þ': "TH",
+		},
+	}
+
+	// NormalizerFrench removes accents and cedillas and splits the Æ and Œ ligatures.
+	NormalizerFrench = FoldingNormalizer{
+		Replacements: map[rune]string{
+			'Æ': "AE",
+			'Œ': "OE",
+		},
+	}
+
+	// NormalizerGerman replaces ß with SS and removes umlauts (e.g. Ä becomes A).
+	NormalizerGerman = FoldingNormalizer{
+		Replacements: map[rune]string{
+			'ẞ': "SS",
+			'ß': "SS",
+		},
+	}
+
+	// NormalizerGermanExpanded replaces ß with SS and expands umlauts (e.g. Ä becomes AE)
+	// as is conventional in German crosswords.
+	NormalizerGermanExpanded = FoldingNormalizer{
+		Replacements: map[rune]string{
+			'ẞ': "SS",
+			'ß': "SS",
+			'Ä': "AE",
+			'Ö': "OE",
+			'Ü': "UE",
+		},
+	}
+
+	// NormalizerSpanish removes accents but keeps Ñ as a distinct letter.
+	NormalizerSpanish = FoldingNormalizer{
+		Keep: []rune{'Ñ'},
+	}
+)
@@ -0,0 +1,57 @@
+package crossword
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer Normalizer
+		word       string
+		want       string
+	}{
+		{name: "fold accents", normalizer: NormalizerFold, word: "École été", want: "Ecole ete"},
+		{name: "fold combining accents", normalizer: NormalizerFold, word: "ÉCOLE", want: "ECOLE"},
+		{name: "fold replacements", normalizer: NormalizerFold, word: "Øresund Straße", want: "Oresund Strasse"},
+		{name: "french ligatures", normalizer: NormalizerFrench, word: "Œuvre cœur", want: "OEuvre coeur"},
+		{name: "german sharp s", normalizer: NormalizerGerman, word: "STRAẞE Größe", want: "STRASSE Grosse"},
+		{name: "german expanded umlauts", normalizer: NormalizerGermanExpanded, word: "ÄRGER Übel", want: "AERGER UEbel"},
+		{name: "spanish keeps ñ", normalizer: NormalizerSpanish, word: "Año pingüino", want: "Año pinguino"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualValues(t, tt.want, tt.normalizer.Normalize(tt.word))
+		})
+	}
+}
+
+func TestGenerator_Generate_normalized(t *testing.T) {
+	cw := NewGenerator(7).Generate(
+		[]Word{{Word: "École"}, {Word: "été"}, {Word: "straße"}},
+		1,
+		WithNormalizer(NormalizerGerman),
+	)
+	require.Len(t, cw.Words, 3)
+	require.EqualValues(t, Word{Word: "STRASSE", Original: "STRAßE", LettersCounts: []int{7}}, cw.Words[0].Word)
+	require.EqualValues(t, Word{Word: "ECOLE", Original: "ÉCOLE", LettersCounts: []int{5}}, cw.Words[1].Word)
+	require.EqualValues(t, Word{Word: "ETE", Original: "ÉTÉ", LettersCounts: []int{3}}, cw.Words[2].Word)
+
+	// the original spelling is only shown for words where it has the same number of characters
+	require.EqualValues(t, strings.TrimSpace(`
+STRASSÉ
+######C
+######O
+######L
+####ÉTÉ
+#######
+#######`), strings.TrimSpace(RenderText(cw, WithAllSolved(true), WithOriginalSpelling(true))))
+
+	cw = NewGenerator(7).Generate([]Word{{Word: "cœur"}, {Word: "garçon"}}, 1, WithNormalizer(NormalizerFrench))
+	require.Len(t, cw.Words, 2)
+	require.EqualValues(t, Word{Word: "GARCON", Original: "GARÇON", LettersCounts: []int{6}}, cw.Words[0].Word)
+	require.EqualValues(t, Word{Word: "COEUR", Original: "CŒUR", LettersCounts: []int{5}}, cw.Words[1].Word)
+}
@@ -0,0 +1,299 @@
+package crossword
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfClueFontSize   = 10.0 // points
+	pdfTitleFontSize  = 16.0 // points
+	pdfGridLineWidth  = 0.2  // mm
+	pdfColumnGap      = 6.0  // mm
+	pdfMaxGridPortion = 0.6
+)
+
+// PageSize is the paper size of a PDF.
+type PageSize string
+
+const (
+	PageA4     PageSize = "A4"
+	PageLetter PageSize = "Letter"
+)
+
+// WithPageSize sets the paper size used by RenderPDF. The default is A4.
+func WithPageSize(size PageSize) RenderOption {
+	return func(opts *renderOpts) {
+		opts.pageSize = size
+	}
+}
+
+// WithPageMargin sets the margin around each page of a PDF in millimetres. The default is 15mm.
+func WithPageMargin(margin float64) RenderOption {
+	return func(opts *renderOpts) {
+		opts.pageMargin = margin
+	}
+}
+
+// WithSolutionPage adds a page to the end of a PDF with the grid solved, as if rendered WithAllSolved.
+func WithSolutionPage(solution bool) RenderOption {
+	return func(opts *renderOpts) {
+		opts.solutionPage = solution
+	}
+}
+
+// RenderPDF writes the crossword as a PDF for printing. The grid is drawn at the top of the first page with
+// the clues in two columns beneath it, continuing onto further pages if they don't fit. The colors of the
+// grid and the solved state follow the same options as RenderImage, but the page is always white so the
+// clues are printed in the word color and the border is replaced by the page margin. The fonts are
+// embedded, so they must be given to WithFont, WithLabelFont and WithClueFont as TrueType font files.
+func RenderPDF(w io.Writer, c *Crossword, opts ...RenderOption) error {
+	options := resolveRenderOptions(opts...)
+	if err := options.loadFonts(); err != nil {
+		return err
+	}
+	applyRandomSolved(c, options)
+
+	pdf := fpdf.New("P", "mm", string(options.pageSize), "")
+	pdf.SetMargins(options.pageMargin, options.pageMargin, options.pageMargin)
+	pdf.SetAutoPageBreak(false, options.pageMargin)
+	fonts, err := pdfFonts(pdf, options)
+	if err != nil {
+		return err
+	}
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to create PDF: %w", err)
+	}
+	if c.Metadata.Title != "" {
+		pdf.SetTitle(pdfText(c.Metadata.Title), true)
+	}
+	if c.Metadata.Author != "" {
+		pdf.SetAuthor(pdfText(c.Metadata.Author), true)
+	}
+
+	pdf.AddPage()
+	top := pdfHeader(pdf, c, c.Metadata.Title, fonts, options)
+	bottom := pdfGrid(pdf, c, top, fonts, options, options.solveAll)
+	pdfClues(pdf, c, bottom+pdfColumnGap, fonts, options)
+
+	if options.solutionPage {
+		pdf.AddPage()
+		title := "Solution"
+		if c.Metadata.Title != "" {
+			title = fmt.Sprintf("%s: %s", c.Metadata.Title, title)
+		}
+		pdfGrid(pdf, c, pdfHeader(pdf, c, title, fonts, options), fonts, options, true)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// pdfFontFamilies are the names of the fonts registered with the PDF.
+type pdfFontFamilies struct {
+	letters, labels, clues string
+}
+
+// pdfFonts embeds the fonts of the letters, labels and clues in the PDF.
+func pdfFonts(pdf *fpdf.Fpdf, options *renderOpts) (pdfFontFamilies, error) {
+	names := map[*renderFont]string{}
+	family := func(f *renderFont) (string, error) {
+		if name, ok := names[f]; ok {
+			return name, nil
+		}
+		if f.data == nil {
+			return "", errors.New("a font given as a *truetype.Font cannot be embedded in a PDF, give the font file instead")
+		}
+		name := fmt.Sprintf("font%d", len(names)+1)
+		pdf.AddUTF8FontFromBytes(name, "", f.data)
+		names[f] = name
+		return name, nil
+	}
+
+	var fonts pdfFontFamilies
+	var err error
+	if fonts.letters, err = family(options.font); err != nil {
+		return fonts, err
+	}
+	if fonts.labels, err = family(options.labelFont); err != nil {
+		return fonts, err
+	}
+	fonts.clues, err = family(options.clueFont)
+	return fonts, err
+}
+
+// pdfHeader writes the title and author at the top of the page, returning the position below them.
+func pdfHeader(pdf *fpdf.Fpdf, c *Crossword, title string, fonts pdfFontFamilies, options *renderOpts) float64 {
+	y := options.pageMargin
+	pdfSetColor(pdf.SetTextColor, options.wordColor)
+	if title != "" {
+		pdf.SetFont(fonts.clues, "", pdfTitleFontSize)
+		_, size := pdf.GetFontSize()
+		y += size
+		pdf.Text(options.pageMargin, y, pdfText(title))
+		y += size / 2
+	}
+	if c.Metadata.Author != "" {
+		pdf.SetFont(fonts.clues, "", pdfClueFontSize)
+		_, size := pdf.GetFontSize()
+		y += size
+		pdf.Text(options.pageMargin, y, pdfText(c.Metadata.Author))
+		y += size
+	}
+	return y
+}
+
+// pdfGrid draws the grid centered below top, returning the position of its bottom edge.
+func pdfGrid(pdf *fpdf.Fpdf, c *Crossword, top float64, fonts pdfFontFamilies, options *renderOpts, solveAll bool) float64 {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*options.pageMargin
+	contentHeight := pageHeight - top - options.pageMargin
+
+	cellSize := min(contentWidth/float64(c.Grid.Width()), contentHeight*pdfMaxGridPortion/float64(c.Grid.Height()))
+	left := options.pageMargin + (contentWidth-cellSize*float64(c.Grid.Width()))/2
+	labelFontSize := cellSize * 0.25
+	wordFontSize := cellSize * options.wordFontSizePcnt
+
+	pdf.SetLineWidth(pdfGridLineWidth)
+	pdfSetColor(pdf.SetDrawColor, options.wordColor)
+	for gridY := range c.Grid {
+		for gridX, cell := range c.Grid[gridY] {
+			if !c.Mask.Allowed(gridX, gridY) {
+				continue
+			}
+			cellX, cellY := left+float64(gridX)*cellSize, top+float64(gridY)*cellSize
+			if cell.Empty() {
+				pdfSetColor(pdf.SetFillColor, options.backgroundColor)
+				pdf.Rect(cellX, cellY, cellSize, cellSize, "FD")
+				continue
+			}
+			pdfSetColor(pdf.SetFillColor, options.wordBackgroundColor)
+			pdf.Rect(cellX, cellY, cellSize, cellSize, "FD")
+
+			var solved bool
+			offset := 0.0
+			pdf.SetFont(fonts.labels, "", 0)
+			pdf.SetFontUnitSize(labelFontSize)
+			pdfSetColor(pdf.SetTextColor, options.labelColor)
+			for _, pl := range c.CellPlacements(gridX, gridY) {
+				if pl.X == gridX && pl.Y == gridY {
+					pdf.Text(cellX+labelFontSize*0.1, cellY+labelFontSize+offset, pdfText(pl.ClueID()))
+					offset = cellSize - (labelFontSize * 1.4)
+				}
+				if pl.Solved || slices.Contains(pl.Word.CharacterHints, cell.CharIdx) {
+					solved = true
+				}
+			}
+			if solved || solveAll {
+				pdf.SetFont(fonts.letters, "", 0)
+				pdf.SetFontUnitSize(wordFontSize)
+				pdfSetColor(pdf.SetTextColor, options.wordColor)
+				text := pdfText(strings.ToUpper(c.cellText(gridX, gridY, options.originalSpelling)))
+				// the baseline is offset by roughly half the cap height to center the letter vertically
+				pdf.Text(cellX+(cellSize-pdf.GetStringWidth(text))/2, cellY+cellSize/2+wordFontSize*0.35, text)
+			}
+			if cell.Circled {
+				pdf.Circle(cellX+cellSize/2, cellY+cellSize/2, cellSize*0.45, "D")
+			}
+		}
+	}
+	return top + cellSize*float64(c.Grid.Height())
+}
+
+// pdfClues writes the clues in columns starting at top, adding pages as needed.
+func pdfClues(pdf *fpdf.Fpdf, c *Crossword, top float64, fonts pdfFontFamilies, options *renderOpts) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	bottom := pageHeight - options.pageMargin
+	colWidth := (pageWidth - 2*options.pageMargin - pdfColumnGap) / 2
+
+	pdf.SetFont(fonts.clues, "", pdfClueFontSize)
+	_, fontSize := pdf.GetFontSize()
+	lineHeight := fontSize * 1.2
+	checkboxSize := fontSize * 0.8
+	checkboxSpace := checkboxSize + fontSize/2
+	pdfSetColor(pdf.SetTextColor, options.wordColor)
+	pdfSetColor(pdf.SetDrawColor, options.wordColor)
+	pdfSetColor(pdf.SetFillColor, options.wordColor)
+	pdf.SetLineWidth(pdfGridLineWidth)
+
+	column, y := 0, top
+	// reserve moves to the next column or page if the height doesn't fit in the current column.
+	reserve := func(height float64) float64 {
+		if y+height > bottom && y > top {
+			column++
+			y = top
+			if column == 2 {
+				pdf.AddPage()
+				column, top = 0, options.pageMargin
+				y = top
+			}
+		}
+		x := options.pageMargin + float64(column)*(colWidth+pdfColumnGap)
+		y += height
+		return x
+	}
+
+	for _, group := range []struct {
+		title    string
+		vertical bool
+	}{{"ACROSS", false}, {"DOWN", true}} {
+		x := reserve(lineHeight * 1.5)
+		pdf.Text(x, y-lineHeight*0.5, group.title)
+		for _, pl := range c.Words {
+			if pl.Vertical != group.vertical {
+				continue
+			}
+			lines := pdf.SplitText(pdfText(clueText(pl)), colWidth-checkboxSpace)
+			height := float64(len(lines)) * lineHeight
+
+			// the lines of a clue are kept in one column unless the clue is taller than a column
+			split := height > bottom-top
+			if !split {
+				x = reserve(height)
+				y -= height
+			}
+			style := "D"
+			if pl.Solved {
+				style = "FD"
+			}
+			for k, line := range lines {
+				if split {
+					x = reserve(lineHeight)
+				} else {
+					y += lineHeight
+				}
+				lineTop := y - lineHeight
+				if k == 0 {
+					pdf.Rect(x, lineTop+(lineHeight-checkboxSize)/2, checkboxSize, checkboxSize, style)
+				}
+				pdf.Text(x+checkboxSpace, lineTop+fontSize, line)
+			}
+			y += lineHeight / 3
+		}
+	}
+}
+
+// pdfSetColor calls set with the 8-bit components of the color, ignoring its alpha.
+func pdfSetColor(set func(r, g, b int), cl color.Color) {
+	nrgba := color.NRGBAModel.Convert(cl).(color.NRGBA)
+	set(int(nrgba.R), int(nrgba.G), int(nrgba.B))
+}
+
+// pdfText replaces the characters outside the Basic Multilingual Plane, which the PDF writer cannot measure.
+func pdfText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0xffff {
+			return utf8.RuneError
+		}
+		return r
+	}, s)
+}
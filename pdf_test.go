@@ -0,0 +1,46 @@
+package crossword
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pdfPages(pdf []byte) int {
+	return bytes.Count(pdf, []byte("/Type /Page\n"))
+}
+
+func TestRenderPDF(t *testing.T) {
+	t.Run("single page", func(t *testing.T) {
+		cw := testCrossword(t)
+		cw.Metadata = Metadata{Title: "Fruit", Author: "Author"}
+		buff := &bytes.Buffer{}
+		require.NoError(t, RenderPDF(buff, cw))
+		require.True(t, bytes.HasPrefix(buff.Bytes(), []byte("%PDF-")))
+		require.Equal(t, 1, pdfPages(buff.Bytes()))
+		require.Contains(t, buff.String(), "/MediaBox [0 0 595.28 841.89]")
+		require.Contains(t, buff.String(), "/FontFile2")
+	})
+	t.Run("clues overflow and solution page", func(t *testing.T) {
+		cw := testCrossword(t)
+		for k := range cw.Words {
+			cw.Words[k].Word.Clue = strings.Repeat("a very long clue ", 80)
+		}
+		buff := &bytes.Buffer{}
+		require.NoError(t, RenderPDF(buff, cw, WithPageSize(PageLetter), WithPageMargin(10), WithSolutionPage(true)))
+		require.Equal(t, 3, pdfPages(buff.Bytes()))
+		require.Contains(t, buff.String(), "/MediaBox [0 0 612.00 792.00]")
+	})
+	t.Run("clue taller than a column", func(t *testing.T) {
+		cw := testCrossword(t)
+		cw.Words[0].Word.Clue = strings.Repeat("a very long clue ", 2000)
+		buff := &bytes.Buffer{}
+		require.NoError(t, RenderPDF(buff, cw))
+		require.Greater(t, pdfPages(buff.Bytes()), 2)
+	})
+	t.Run("invalid page size", func(t *testing.T) {
+		require.Error(t, RenderPDF(&bytes.Buffer{}, testCrossword(t), WithPageSize("Napkin")))
+	})
+}
@@ -0,0 +1,611 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	puzMagic          = "ACROSS&DOWN\x00"
+	puzVersion        = "1.3\x00"
+	puzHeaderSize     = 0x34
+	puzBlock          = '.'
+	puzEmpty          = '-'
+	puzTypeNormal     = 0x0001
+	puzScrambledTag   = 0x0004
+	puzMaxSize        = 255
+	puzMinWordLength  = 2
+	puzScrambleKeyLen = 4
+
+	puzExtraRebusGrid  = "GRBS"
+	puzExtraRebusTable = "RTBL"
+	puzExtraMarkup     = "GEXT"
+	puzMarkupCircled   = 0x80
+)
+
+// ErrInvalidPUZ is returned by ReadPUZ when the file cannot be parsed.
+var ErrInvalidPUZ = errors.New("invalid puz file")
+
+// ErrPUZChecksum is returned by ReadPUZ when one of the checksums in the file does not match its contents.
+var ErrPUZChecksum = errors.New("puz checksum mismatch")
+
+// ErrPUZScrambled is returned by ReadPUZ when the solution is scrambled and no key was given
+// with WithPUZScrambleKey, or the key was incorrect.
+var ErrPUZScrambled = errors.New("puz solution is scrambled")
+
+type PUZOption func(opts *puzOpts)
+
+// WithPUZScrambleKey scrambles the solution when writing a .puz file so it can only be checked,
+// not revealed, without the key. When reading, the key is used to unscramble the solution.
+// The key must be four digits from 1 to 9 e.g. 1234 and the solution may only contain the letters A-Z.
+func WithPUZScrambleKey(key int) PUZOption {
+	return func(opts *puzOpts) {
+		opts.scrambleKey = key
+	}
+}
+
+// WithPUZIgnoreChecksums reads .puz files even if their checksums do not match the contents.
+func WithPUZIgnoreChecksums(ignore bool) PUZOption {
+	return func(opts *puzOpts) {
+		opts.ignoreChecksums = ignore
+	}
+}
+
+type puzOpts struct {
+	scrambleKey     int
+	ignoreChecksums bool
+}
+
+func resolvePUZOptions(opts []PUZOption) *puzOpts {
+	resolved := &puzOpts{}
+	for _, o := range opts {
+		o(resolved)
+	}
+	return resolved
+}
+
+// puzHeader is the fixed size header at the start of a .puz file.
+type puzHeader struct {
+	Checksum          uint16
+	Magic             [12]byte
+	CIBChecksum       uint16
+	MaskedLowChecks   [4]byte
+	MaskedHighChecks  [4]byte
+	Version           [4]byte
+	Reserved1C        uint16
+	ScrambledChecksum uint16
+	Reserved20        [12]byte
+	Width             uint8
+	Height            uint8
+	NumClues          uint16
+	PuzzleType        uint16
+	ScrambledTag      uint16
+}
+
+// WritePUZ writes the crossword in the Across Lite .puz format. Empty cells are written as blocks and
+// every run of two or more letters must be one of the crossword's words since the format numbers the
+// words from the grid. Clues and Metadata are encoded as Windows-1252 so the grid and text must only
+// use characters from that character set. Solved words and character hints are filled in the player's grid.
+func WritePUZ(w io.Writer, cw *Crossword, opts ...PUZOption) error {
+	options := resolvePUZOptions(opts)
+
+	width, height := cw.Grid.Width(), cw.Grid.Height()
+	if width > puzMaxSize || height > puzMaxSize {
+		return fmt.Errorf("puz grid cannot be larger than %dx%d", puzMaxSize, puzMaxSize)
+	}
+
+	clues, err := puzClues(cw)
+	if err != nil {
+		return err
+	}
+
+	blocks := cw.blocks()
+	solution := make([]byte, 0, width*height)
+	state := make([]byte, 0, width*height)
+	for y := range height {
+		for x := range width {
+			if blocks[y][x] {
+				solution = append(solution, puzBlock)
+				state = append(state, puzBlock)
+				continue
+			}
+			char, err := puzEncode(string(cw.Grid[y][x].Char))
+			if err != nil || len(char) != 1 {
+				return fmt.Errorf("puz cannot represent the character at %d,%d: %q", x, y, cw.Grid[y][x].Char)
+			}
+			solution = append(solution, char...)
+			if cw.revealed(x, y) {
+				state = append(state, char...)
+			} else {
+				state = append(state, puzEmpty)
+			}
+		}
+	}
+
+	var text [][]byte
+	for _, s := range append([]string{cw.Metadata.Title, cw.Metadata.Author, cw.Metadata.Copyright}, append(clues, cw.Metadata.Notes)...) {
+		encoded, err := puzEncode(s)
+		if err != nil {
+			return fmt.Errorf("puz cannot represent the text %q: %w", s, err)
+		}
+		text = append(text, encoded)
+	}
+
+	header := puzHeader{
+		Width:      uint8(width),
+		Height:     uint8(height),
+		NumClues:   uint16(len(clues)),
+		PuzzleType: puzTypeNormal,
+	}
+	copy(header.Magic[:], puzMagic)
+	copy(header.Version[:], puzVersion)
+
+	if options.scrambleKey != 0 {
+		header.ScrambledChecksum, err = puzScramble(solution, width, height, options.scrambleKey)
+		if err != nil {
+			return err
+		}
+		header.ScrambledTag = puzScrambledTag
+	}
+	header.setChecksums(solution, state, text)
+
+	buf := &bytes.Buffer{}
+	if err := binary.Write(buf, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	buf.Write(solution)
+	buf.Write(state)
+	for _, s := range text {
+		buf.Write(s)
+		buf.WriteByte(0)
+	}
+	if err := puzWriteCellExtras(buf, cw); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// puzWriteCellExtras writes the optional sections for circled and rebus cells if there are any.
+func puzWriteCellExtras(buf *bytes.Buffer, cw *Crossword) error {
+	var markup, rebusGrid []byte
+	var rebusTable []string
+	for y := range cw.Grid {
+		for _, cell := range cw.Grid[y] {
+			var circled, rebus byte
+			if cell.Circled {
+				circled = puzMarkupCircled
+			}
+			if cell.Rebus != "" {
+				idx := slices.Index(rebusTable, cell.Rebus)
+				if idx == -1 {
+					if len(rebusTable) == math.MaxUint8 {
+						return fmt.Errorf("puz cannot have more than %d different rebus answers", math.MaxUint8)
+					}
+					rebusTable = append(rebusTable, cell.Rebus)
+					idx = len(rebusTable) - 1
+				}
+				rebus = byte(idx + 1)
+			}
+			markup = append(markup, circled)
+			rebusGrid = append(rebusGrid, rebus)
+		}
+	}
+	if len(rebusTable) > 0 {
+		var table strings.Builder
+		for k, rebus := range rebusTable {
+			fmt.Fprintf(&table, "%2d:%s;", k, rebus)
+		}
+		encoded, err := puzEncode(table.String())
+		if err != nil {
+			return fmt.Errorf("puz cannot represent the rebus table %q: %w", table.String(), err)
+		}
+		puzWriteExtra(buf, puzExtraRebusGrid, rebusGrid)
+		puzWriteExtra(buf, puzExtraRebusTable, encoded)
+	}
+	if slices.Contains(markup, puzMarkupCircled) {
+		puzWriteExtra(buf, puzExtraMarkup, markup)
+	}
+	return nil
+}
+
+// puzClues returns the clue for each word in the order used by .puz files i.e. by number, with the
+// across clue first if both words have the same number.
+func puzClues(cw *Crossword) ([]string, error) {
+	slots := findSlots(cw.blocks(), puzMinWordLength)
+	if len(slots) != len(cw.Words) {
+		return nil, fmt.Errorf("puz grid has %d words but the crossword has %d, all words must be at least %d letters and not adjacent to others", len(slots), len(cw.Words), puzMinWordLength)
+	}
+	clues := make([]string, len(slots))
+	for k, sl := range slots {
+		found := false
+		for _, pl := range cw.Words {
+			if pl.X == sl.X && pl.Y == sl.Y && pl.Vertical == sl.Vertical && pl.Word.Len() == sl.Length {
+				clues[k] = pl.Word.Clue
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("puz grid has a word at %d,%d which is not in the crossword", sl.X, sl.Y)
+		}
+	}
+	return clues, nil
+}
+
+// setChecksums calculates the checksums covering the header, grids and text.
+func (h *puzHeader) setChecksums(solution, state []byte, text [][]byte) {
+	h.CIBChecksum = puzChecksum(h.cib(), 0)
+
+	solutionChecksum := puzChecksum(solution, 0)
+	stateChecksum := puzChecksum(state, 0)
+	textChecksum := puzTextChecksum(text, 0)
+
+	checksum := puzChecksum(solution, h.CIBChecksum)
+	checksum = puzChecksum(state, checksum)
+	h.Checksum = puzTextChecksum(text, checksum)
+
+	h.MaskedLowChecks = [4]byte{
+		'I' ^ byte(h.CIBChecksum),
+		'C' ^ byte(solutionChecksum),
+		'H' ^ byte(stateChecksum),
+		'E' ^ byte(textChecksum),
+	}
+	h.MaskedHighChecks = [4]byte{
+		'A' ^ byte(h.CIBChecksum>>8),
+		'T' ^ byte(solutionChecksum>>8),
+		'E' ^ byte(stateChecksum>>8),
+		'D' ^ byte(textChecksum>>8),
+	}
+}
+
+// cib returns the bytes of the header describing the puzzle which are covered by the CIB checksum.
+func (h *puzHeader) cib() []byte {
+	cib := make([]byte, 8)
+	cib[0], cib[1] = h.Width, h.Height
+	binary.LittleEndian.PutUint16(cib[2:], h.NumClues)
+	binary.LittleEndian.PutUint16(cib[4:], h.PuzzleType)
+	binary.LittleEndian.PutUint16(cib[6:], h.ScrambledTag)
+	return cib
+}
+
+func puzChecksum(data []byte, checksum uint16) uint16 {
+	for _, b := range data {
+		if checksum&1 == 1 {
+			checksum = (checksum >> 1) | 0x8000
+		} else {
+			checksum >>= 1
+		}
+		checksum += uint16(b)
+	}
+	return checksum
+}
+
+// puzTextChecksum calculates the checksum of the title, author, copyright, clues and notes. The
+// metadata is only included if it is set, along with its null terminator, which clues never include.
+func puzTextChecksum(text [][]byte, checksum uint16) uint16 {
+	for k, s := range text {
+		isClue := k >= 3 && k < len(text)-1
+		if isClue {
+			checksum = puzChecksum(s, checksum)
+		} else if len(s) > 0 {
+			checksum = puzChecksum([]byte{0}, puzChecksum(s, checksum))
+		}
+	}
+	return checksum
+}
+
+func puzEncode(s string) ([]byte, error) {
+	encoded, err := charmap.Windows1252.NewEncoder().String(s)
+	return []byte(encoded), err
+}
+
+func puzDecode(b []byte) string {
+	decoded, _ := charmap.Windows1252.NewDecoder().Bytes(b)
+	return string(decoded)
+}
+
+// puzScrambleKey splits the key into its digits.
+func puzScrambleKey(key int) ([]int, error) {
+	if key < 1111 || key > 9999 {
+		return nil, fmt.Errorf("puz scramble key must be 4 digits from 1 to 9: %d", key)
+	}
+	digits := make([]int, puzScrambleKeyLen)
+	for k := puzScrambleKeyLen - 1; k >= 0; k-- {
+		digits[k] = key % 10
+		if digits[k] == 0 {
+			return nil, fmt.Errorf("puz scramble key must be 4 digits from 1 to 9: %d", key)
+		}
+		key /= 10
+	}
+	return digits, nil
+}
+
+// puzScrambledLetters returns the letters of the solution in column order along with their
+// position in the solution.
+func puzScrambledLetters(solution []byte, width, height int) ([]byte, []int) {
+	var letters []byte
+	var positions []int
+	for x := range width {
+		for y := range height {
+			if solution[y*width+x] != puzBlock {
+				letters = append(letters, solution[y*width+x])
+				positions = append(positions, y*width+x)
+			}
+		}
+	}
+	return letters, positions
+}
+
+// puzScramble scrambles the solution in place using the key and returns the checksum of the
+// original letters which is used to check the key when unscrambling.
+func puzScramble(solution []byte, width, height int, key int) (uint16, error) {
+	digits, err := puzScrambleKey(key)
+	if err != nil {
+		return 0, err
+	}
+	letters, positions := puzScrambledLetters(solution, width, height)
+	for _, l := range letters {
+		if l < 'A' || l > 'Z' {
+			return 0, fmt.Errorf("puz solution can only be scrambled if it contains the letters A-Z: %q", l)
+		}
+	}
+	checksum := puzChecksum(letters, 0)
+
+	for _, digit := range digits {
+		for k := range letters {
+			letters[k] = 'A' + (letters[k]-'A'+byte(digits[k%puzScrambleKeyLen]))%26
+		}
+		rotate := digit % max(1, len(letters))
+		letters = slices.Concat(letters[rotate:], letters[:rotate])
+
+		// interleave the second half with the first
+		mid := len(letters) / 2
+		shuffled := make([]byte, 0, len(letters))
+		for k := range mid {
+			shuffled = append(shuffled, letters[mid+k], letters[k])
+		}
+		if len(letters)%2 == 1 {
+			shuffled = append(shuffled, letters[len(letters)-1])
+		}
+		letters = shuffled
+	}
+	for k, pos := range positions {
+		solution[pos] = letters[k]
+	}
+	return checksum, nil
+}
+
+// puzUnscramble reverses puzScramble, returning ErrPUZScrambled if the letters do not match the checksum.
+func puzUnscramble(solution []byte, width, height int, key int, checksum uint16) error {
+	digits, err := puzScrambleKey(key)
+	if err != nil {
+		return err
+	}
+	letters, positions := puzScrambledLetters(solution, width, height)
+	for k := len(digits) - 1; k >= 0; k-- {
+		unshuffled := make([]byte, 0, len(letters))
+		for i := 1; i < len(letters); i += 2 {
+			unshuffled = append(unshuffled, letters[i])
+		}
+		for i := 0; i < len(letters); i += 2 {
+			unshuffled = append(unshuffled, letters[i])
+		}
+		rotate := len(letters) - digits[k]%max(1, len(letters))
+		letters = slices.Concat(unshuffled[rotate:], unshuffled[:rotate])
+		for i := range letters {
+			letters[i] = 'A' + (letters[i]-'A'+26-byte(digits[i%puzScrambleKeyLen]))%26
+		}
+	}
+	if puzChecksum(letters, 0) != checksum {
+		return fmt.Errorf("%w: incorrect key", ErrPUZScrambled)
+	}
+	for k, pos := range positions {
+		solution[pos] = letters[k]
+	}
+	return nil
+}
+
+// ReadPUZ reads a crossword from the Across Lite .puz format. Blocks are read as empty cells and
+// the words are numbered from the grid. Words filled in the player's grid are marked as solved, and
+// any partially filled words have the filled characters as hints. Circled cells and rebus answers
+// are kept on the grid's cells.
+//
+// Files which cannot be parsed return an error wrapping ErrInvalidPUZ. The checksums are validated
+// unless WithPUZIgnoreChecksums is used and a mismatch returns an error wrapping ErrPUZChecksum.
+func ReadPUZ(r io.Reader, opts ...PUZOption) (*Crossword, error) {
+	options := resolvePUZOptions(opts)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read puz: %w", err)
+	}
+
+	// some files have a preamble before the header
+	start := bytes.Index(data, []byte(puzMagic)) - 2
+	if start < 0 {
+		return nil, fmt.Errorf("%w: file magic not found", ErrInvalidPUZ)
+	}
+	data = data[start:]
+	if len(data) < puzHeaderSize {
+		return nil, fmt.Errorf("%w: header is incomplete", ErrInvalidPUZ)
+	}
+	header := puzHeader{}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPUZ, err)
+	}
+	width, height := int(header.Width), int(header.Height)
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("%w: grid is empty", ErrInvalidPUZ)
+	}
+	data = data[puzHeaderSize:]
+	if len(data) < 2*width*height {
+		return nil, fmt.Errorf("%w: file is too short for a %dx%d grid", ErrInvalidPUZ, width, height)
+	}
+	solution := bytes.Clone(data[:width*height])
+	state := data[width*height : 2*width*height]
+	data = data[2*width*height:]
+
+	// the title, author, copyright, clues and notes are null terminated
+	var text [][]byte
+	for range 3 + int(header.NumClues) + 1 {
+		s, rest, found := bytes.Cut(data, []byte{0})
+		if !found && len(text) < 3+int(header.NumClues) {
+			return nil, fmt.Errorf("%w: expected %d clues but found %d", ErrInvalidPUZ, header.NumClues, max(0, len(text)-3))
+		}
+		text = append(text, s)
+		data = rest
+	}
+
+	extras, err := puzReadExtras(data, width*height, options.ignoreChecksums)
+	if err != nil {
+		return nil, err
+	}
+	if !options.ignoreChecksums {
+		if err := header.validateChecksums(solution, state, text); err != nil {
+			return nil, err
+		}
+	}
+
+	if header.ScrambledTag&puzScrambledTag != 0 {
+		if options.scrambleKey == 0 {
+			return nil, ErrPUZScrambled
+		}
+		if err := puzUnscramble(solution, width, height, options.scrambleKey, header.ScrambledChecksum); err != nil {
+			return nil, err
+		}
+	}
+
+	cw := &Crossword{
+		Grid: NewRectGrid(width, height),
+		Metadata: Metadata{
+			Title:     puzDecode(text[0]),
+			Author:    puzDecode(text[1]),
+			Copyright: puzDecode(text[2]),
+			Notes:     puzDecode(text[len(text)-1]),
+		},
+	}
+	blocks := make([][]bool, height)
+	for y := range height {
+		blocks[y] = make([]bool, width)
+		for x := range width {
+			if solution[y*width+x] == puzBlock {
+				blocks[y][x] = true
+				continue
+			}
+			cw.Grid[y][x] = Cell{
+				Char:    []rune(puzDecode(solution[y*width+x : y*width+x+1]))[0],
+				Circled: extras.markup != nil && extras.markup[y*width+x]&puzMarkupCircled != 0,
+			}
+			if extras.rebusGrid != nil && extras.rebusGrid[y*width+x] > 0 {
+				rebus, ok := extras.rebusTable[int(extras.rebusGrid[y*width+x])-1]
+				if !ok {
+					return nil, fmt.Errorf("%w: rebus %d at %d,%d is not in the rebus table", ErrInvalidPUZ, extras.rebusGrid[y*width+x]-1, x, y)
+				}
+				cw.Grid[y][x].Rebus = rebus
+			}
+		}
+	}
+
+	slots := findSlots(blocks, puzMinWordLength)
+	if len(slots) != int(header.NumClues) {
+		return nil, fmt.Errorf("%w: grid has %d words but there are %d clues", ErrInvalidPUZ, len(slots), header.NumClues)
+	}
+	clues := make([]string, len(slots))
+	for k := range slots {
+		clues[k] = puzDecode(text[3+k])
+	}
+	cw.placeSlots(slots, clues, func(x, y int) bool {
+		return state[y*width+x] != puzEmpty
+	})
+	return cw, nil
+}
+
+// validateChecksums checks each of the checksums in the header match the contents of the file.
+func (h *puzHeader) validateChecksums(solution, state []byte, text [][]byte) error {
+	if string(h.Version[:3]) < "1.3" {
+		// notes were not included in the checksums of older versions
+		text = slices.Clone(text)
+		text[len(text)-1] = nil
+	}
+	expected := *h
+	expected.setChecksums(solution, state, text)
+	switch {
+	case expected.CIBChecksum != h.CIBChecksum:
+		return fmt.Errorf("%w: header checksum is %#04x but should be %#04x", ErrPUZChecksum, h.CIBChecksum, expected.CIBChecksum)
+	case expected.Checksum != h.Checksum:
+		return fmt.Errorf("%w: file checksum is %#04x but should be %#04x", ErrPUZChecksum, h.Checksum, expected.Checksum)
+	case expected.MaskedLowChecks != h.MaskedLowChecks || expected.MaskedHighChecks != h.MaskedHighChecks:
+		return fmt.Errorf("%w: masked checksums do not match", ErrPUZChecksum)
+	}
+	return nil
+}
+
+// puzExtras holds the optional sections following the clues.
+type puzExtras struct {
+	rebusGrid  []byte
+	rebusTable map[int]string
+	markup     []byte
+}
+
+// puzReadExtras reads the optional sections following the clues. Unknown sections are ignored.
+func puzReadExtras(data []byte, cells int, ignoreChecksums bool) (puzExtras, error) {
+	extras := puzExtras{}
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return extras, fmt.Errorf("%w: extra section header is incomplete", ErrInvalidPUZ)
+		}
+		title := string(data[:4])
+		length := int(binary.LittleEndian.Uint16(data[4:]))
+		checksum := binary.LittleEndian.Uint16(data[6:])
+		if len(data) < 8+length+1 {
+			return extras, fmt.Errorf("%w: %s section is incomplete", ErrInvalidPUZ, title)
+		}
+		section := data[8 : 8+length]
+		data = data[8+length+1:]
+
+		if !ignoreChecksums && puzChecksum(section, 0) != checksum {
+			return extras, fmt.Errorf("%w: %s section checksum does not match", ErrPUZChecksum, title)
+		}
+		switch title {
+		case puzExtraRebusGrid, puzExtraMarkup:
+			if len(section) != cells {
+				return extras, fmt.Errorf("%w: %s section should have %d cells but has %d", ErrInvalidPUZ, title, cells, len(section))
+			}
+			if title == puzExtraRebusGrid {
+				extras.rebusGrid = section
+			} else {
+				extras.markup = section
+			}
+		case puzExtraRebusTable:
+			extras.rebusTable = map[int]string{}
+			for _, entry := range strings.Split(strings.TrimSuffix(puzDecode(section), ";"), ";") {
+				key, answer, found := strings.Cut(entry, ":")
+				num, err := strconv.Atoi(strings.TrimSpace(key))
+				if !found || err != nil {
+					return extras, fmt.Errorf("%w: invalid rebus table entry %q", ErrInvalidPUZ, entry)
+				}
+				extras.rebusTable[num] = answer
+			}
+		}
+	}
+	return extras, nil
+}
+
+// puzWriteExtra writes an optional section with the given title.
+func puzWriteExtra(buf *bytes.Buffer, title string, section []byte) {
+	buf.WriteString(title)
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(section)))
+	_ = binary.Write(buf, binary.LittleEndian, puzChecksum(section, 0))
+	buf.Write(section)
+	buf.WriteByte(0)
+}
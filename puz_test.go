@@ -0,0 +1,133 @@
+package crossword
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePUZ(t *testing.T) {
+	cw := testCrossword(t)
+	cw.Metadata = Metadata{Title: "Title", Author: "Author", Copyright: "© 2026", Notes: "Notes"}
+	cw.Words[0].Solved = true
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WritePUZ(buf, cw))
+
+	read, err := ReadPUZ(buf)
+	require.NoError(t, err)
+	require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+	require.Equal(t, RenderText(cw), RenderText(read), "solved words should be restored")
+	require.ElementsMatch(t, placedClues(cw), placedClues(read))
+	require.Equal(t, cw.Metadata, read.Metadata)
+
+	// clues are ordered by their number
+	for k := 1; k < len(read.Words); k++ {
+		require.LessOrEqual(t, read.Words[k-1].ID, read.Words[k].ID)
+	}
+}
+
+func TestWritePUZ_scrambled(t *testing.T) {
+	cw := testCrossword(t)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WritePUZ(buf, cw, WithPUZScrambleKey(1234)))
+	data := buf.Bytes()
+
+	_, err := ReadPUZ(bytes.NewReader(data))
+	require.ErrorIs(t, err, ErrPUZScrambled)
+
+	_, err = ReadPUZ(bytes.NewReader(data), WithPUZScrambleKey(4321))
+	require.ErrorIs(t, err, ErrPUZScrambled)
+
+	read, err := ReadPUZ(bytes.NewReader(data), WithPUZScrambleKey(1234))
+	require.NoError(t, err)
+	require.Equal(t, RenderText(cw, WithAllSolved(true)), RenderText(read, WithAllSolved(true)))
+}
+
+func TestWritePUZ_errors(t *testing.T) {
+	t.Run("unsupported characters", func(t *testing.T) {
+		cw := NewGenerator(5).Generate([]Word{{Word: "γάτα"}}, 1)
+		require.Error(t, WritePUZ(&bytes.Buffer{}, cw))
+	})
+	t.Run("invalid scramble key", func(t *testing.T) {
+		require.Error(t, WritePUZ(&bytes.Buffer{}, testCrossword(t), WithPUZScrambleKey(1034)))
+	})
+	t.Run("single letter word", func(t *testing.T) {
+		cw := NewGenerator(5).Generate([]Word{{Word: "a"}}, 1)
+		require.Error(t, WritePUZ(&bytes.Buffer{}, cw))
+	})
+}
+
+func TestPUZScramble(t *testing.T) {
+	solution := []byte("ABC.DEFGHI.JKLMNOPQR")
+	scrambled := bytes.Clone(solution)
+	checksum, err := puzScramble(scrambled, 5, 4, 9876)
+	require.NoError(t, err)
+	require.NotEqual(t, solution, scrambled)
+	require.Equal(t, byte('.'), scrambled[3])
+
+	require.NoError(t, puzUnscramble(scrambled, 5, 4, 9876, checksum))
+	require.Equal(t, solution, scrambled)
+}
+
+func TestReadPUZ(t *testing.T) {
+	cw := testCrossword(t)
+	cw.Grid[0][0].Circled = true
+	cw.Grid[0][1].Rebus = "PIP"
+	buf := &bytes.Buffer{}
+	require.NoError(t, WritePUZ(buf, cw))
+	valid := buf.Bytes()
+
+	t.Run("circled and rebus cells", func(t *testing.T) {
+		read, err := ReadPUZ(bytes.NewReader(valid))
+		require.NoError(t, err)
+		require.True(t, read.Grid[0][0].Circled)
+		require.False(t, read.Grid[0][1].Circled)
+		require.Equal(t, "PIP", read.Grid[0][1].Rebus)
+		require.Equal(t, 'P', read.Grid[0][1].Char)
+		require.Equal(t, "APIPPLE", RenderText(read, WithAllSolved(true))[:7])
+	})
+	t.Run("preamble is skipped", func(t *testing.T) {
+		read, err := ReadPUZ(bytes.NewReader(append([]byte("junk"), valid...)))
+		require.NoError(t, err)
+		require.Len(t, read.Words, len(cw.Words))
+	})
+	t.Run("not a puz file", func(t *testing.T) {
+		_, err := ReadPUZ(bytes.NewReader([]byte("hello")))
+		require.ErrorIs(t, err, ErrInvalidPUZ)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		for _, length := range []int{puzHeaderSize - 1, puzHeaderSize + 10, len(valid) - 20} {
+			_, err := ReadPUZ(bytes.NewReader(valid[:length]))
+			require.ErrorIs(t, err, ErrInvalidPUZ, "length %d", length)
+		}
+
+		// cut the file part way through the third clue, after the title, author and copyright
+		text := puzHeaderSize + 2*cw.Grid.Width()*cw.Grid.Height()
+		for range 3 + 2 {
+			text += bytes.IndexByte(valid[text:], 0) + 1
+		}
+		_, err := ReadPUZ(bytes.NewReader(valid[:text+1]))
+		require.ErrorIs(t, err, ErrInvalidPUZ)
+		require.ErrorContains(t, err, fmt.Sprintf("expected %d clues but found 2", len(cw.Words)))
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		corrupt := bytes.Clone(valid)
+		corrupt[puzHeaderSize] = 'Z'
+		_, err := ReadPUZ(bytes.NewReader(corrupt))
+		require.ErrorIs(t, err, ErrPUZChecksum)
+
+		read, err := ReadPUZ(bytes.NewReader(corrupt), WithPUZIgnoreChecksums(true))
+		require.NoError(t, err)
+		require.Equal(t, 'Z', read.Grid[0][0].Char)
+	})
+	t.Run("extra section checksum mismatch", func(t *testing.T) {
+		corrupt := bytes.Clone(valid)
+		corrupt[len(corrupt)-2] ^= 0x80
+		_, err := ReadPUZ(bytes.NewReader(corrupt))
+		require.ErrorIs(t, err, ErrPUZChecksum)
+	})
+}
@@ -3,29 +3,21 @@ package crossword
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"image/color"
-	"log"
-	"math/rand"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"math"
+	"math/rand/v2"
 	"slices"
 	"strings"
 
 	"github.com/fogleman/gg"
-	"github.com/golang/freetype/truetype"
-	"golang.org/x/image/font/gofont/goregular"
 )
 
-var font *truetype.Font
-
 const clueSpacing = 10
 
-func init() {
-	var err error
-	font, err = truetype.Parse(goregular.TTF)
-	if err != nil {
-		log.Fatal(err)
-	}
-}
-
 func resolveRenderOptions(opts ...RenderOption) *renderOpts {
 	opt := &renderOpts{
 		backgroundColor:     color.Black,
@@ -36,10 +28,16 @@ func resolveRenderOptions(opts ...RenderOption) *renderOpts {
 		clueColumns:         false,
 		wordFontSizePcnt:    0.5,
 		clueRatio:           0.5,
+		pageSize:            PageA4,
+		pageMargin:          15,
+		scale:               1,
 	}
 	for _, v := range opts {
 		v(opt)
 	}
+	if opt.rand == nil {
+		opt.rand = newSeededRand(rand.Uint64())
+	}
 	return opt
 }
 
@@ -56,6 +54,17 @@ type renderOpts struct {
 	clueColumns         bool
 	wordFontSizePcnt    float64
 	clueRatio           float64
+	rand                *rand.Rand
+	originalSpelling    bool
+	pageSize            PageSize
+	pageMargin          float64
+	solutionPage        bool
+	obfuscateSolution   bool
+	scale               float64
+	font                *renderFont
+	labelFont           *renderFont
+	clueFont            *renderFont
+	err                 error
 }
 
 type RenderOption func(opts *renderOpts)
@@ -90,6 +99,38 @@ func WithRandomSolved() RenderOption {
 	}
 }
 
+// WithRenderSeed seeds the random source used by WithRandomSolved so the same words are always revealed.
+func WithRenderSeed(seed uint64) RenderOption {
+	return func(opts *renderOpts) {
+		opts.rand = newSeededRand(seed)
+	}
+}
+
+// WithRenderRand sets the random source used by WithRandomSolved.
+func WithRenderRand(r *rand.Rand) RenderOption {
+	return func(opts *renderOpts) {
+		opts.rand = r
+	}
+}
+
+// WithOriginalSpelling shows the original spelling of normalized words (see Word.Original) in place
+// of the letters placed in the grid.
+func WithOriginalSpelling(original bool) RenderOption {
+	return func(opts *renderOpts) {
+		opts.originalSpelling = original
+	}
+}
+
+// WithScale sets the number of pixels for each unit of the width and height given to RenderImage e.g. 2 for
+// high density (retina) displays. The border, text and lines are scaled along with the image so it looks
+// the same at any scale. The default is 1 and the scale must be greater than zero. It is not used by the
+// vector renderers.
+func WithScale(scale float64) RenderOption {
+	return func(opts *renderOpts) {
+		opts.scale = scale
+	}
+}
+
 func WithBorder(width float64) RenderOption {
 	return func(opts *renderOpts) {
 		opts.borderWidth = width
@@ -140,17 +181,13 @@ func RenderText(cw *Crossword, opts ...RenderOption) string {
 	out := &bytes.Buffer{}
 	for y := range cw.Grid {
 		for x := range cw.Grid[y] {
-			if cw.Grid[y][x].Empty() {
+			if !cw.Mask.Allowed(x, y) {
+				fmt.Fprintf(out, " ")
+			} else if cw.Grid[y][x].Empty() {
 				fmt.Fprintf(out, "#")
 			} else {
-				var solved bool
-				for _, v := range cw.CellPlacements(x, y) {
-					if v.Solved || slices.Contains(v.Word.CharacterHints, cw.Grid[y][x].CharIdx) {
-						solved = true
-					}
-				}
-				if solved || options.solveAll {
-					fmt.Fprintf(out, "%s", cw.Grid[y][x])
+				if cw.revealed(x, y) || options.solveAll {
+					fmt.Fprintf(out, "%s", cw.cellText(x, y, options.originalSpelling))
 				} else {
 					fmt.Fprintf(out, "?")
 				}
@@ -161,66 +198,81 @@ func RenderText(cw *Crossword, opts ...RenderOption) string {
 	return out.String()
 }
 
+// RenderPNG draws the crossword in a gg drawing context.
+//
+// Deprecated: Use RenderImage or WriteImage, which do not depend on the gg package.
 func RenderPNG(c *Crossword, width, height int, opts ...RenderOption) (*gg.Context, error) {
-	options := resolveRenderOptions(opts...)
+	return renderContext(c, width, height, opts...)
+}
 
-	if options.solveRandom {
-		for k := range c.Words {
-			if rand.Float64() < 0.5 {
-				c.Words[k].Solved = true
-			}
-		}
+// RenderImage draws the crossword as an image of the given width and height multiplied by the scale
+// (see WithScale). Cells outside the Mask are transparent.
+func RenderImage(c *Crossword, width, height int, opts ...RenderOption) (image.Image, error) {
+	dc, err := renderContext(c, width, height, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return dc.Image(), nil
+}
 
-	var gridWidth float64
-	if !options.renderClues {
-		gridWidth = float64(width) - 2*options.borderWidth
-	} else {
-		gridWidth = (float64(width) - 3*options.borderWidth) * (1 - options.clueRatio)
-	}
-	requestedGridWidth := gridWidth
+// ImageEncoder writes an image in a particular format e.g. png.Encode. Encoders which take options, such
+// as jpeg.Encode, or which come from other packages, such as WebP encoders, can be wrapped in a function.
+type ImageEncoder func(w io.Writer, img image.Image) error
 
-	// ensure the grid is square and fits in the vertical space
-	if gridWidth > float64(height)-2*options.borderWidth {
-		gridWidth = float64(height) - 2*options.borderWidth
+// JPEGEncoder encodes images as JPEG with the given quality from 1 to 100. JPEG does not support
+// transparency so cells outside the Mask are black.
+func JPEGEncoder(quality int) ImageEncoder {
+	return func(w io.Writer, img image.Image) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
 	}
+}
 
-	cellWidth := gridWidth / float64(len(c.Grid))
-	cellHeight := cellWidth
-	cellOffset := options.borderWidth
-
-	dc := gg.NewContext(width, height)
+// WriteImage draws the crossword with RenderImage and writes it with the encoder e.g.
+//
+//	err := WriteImage(f, cw, 800, 600, png.Encode, WithScale(2))
+func WriteImage(w io.Writer, c *Crossword, width, height int, encode ImageEncoder, opts ...RenderOption) error {
+	img, err := RenderImage(c, width, height, opts...)
+	if err != nil {
+		return err
+	}
+	if err := encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	return nil
+}
 
-	clueFontSize := 25.0
-	checkboxSize := 10.0
-	checkboxSpace := 15.0
-	var leftPos, maxClueWidth float64
-	if options.renderClues {
-		leftPos = cellOffset + requestedGridWidth + options.borderWidth
-		maxClueWidth = float64(width) - leftPos - options.borderWidth
-
-		// try to find a font size that fits.
-		for clueFontSize > 4 {
-			dc.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: clueFontSize}))
-			checkboxSize = dc.FontHeight() * 0.8
-			checkboxSpace = checkboxSize + clueSpacing
-			clueColumns := 1
-			if options.clueColumns {
-				clueColumns = 2
-			}
-			if measureCluesHeight(c, dc, clueFontSize, maxClueWidth, checkboxSpace, clueColumns, float64(options.borderWidth)) <= float64(height)-2*options.borderWidth {
-				break
-			}
-			clueFontSize -= 0.5
-		}
+func renderContext(c *Crossword, width, height int, opts ...RenderOption) (*gg.Context, error) {
+	options := resolveRenderOptions(opts...)
+	if err := options.loadFonts(); err != nil {
+		return nil, err
+	}
+	scale := options.scale
+	if !(scale > 0) {
+		return nil, fmt.Errorf("scale must be greater than zero but was %v", scale)
+	}
+	width, height = int(math.Round(float64(width)*scale)), int(math.Round(float64(height)*scale))
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("image size must be greater than zero but was %dx%d", width, height)
 	}
+	applyRandomSolved(c, options)
 
+	layout := layoutRender(c, width, height, scale, options)
+	cellWidth, cellHeight, cellOffset := layout.cellSize, layout.cellSize, layout.cellOffset
+	lineWidth := 0.3 * scale
+
+	dc := gg.NewContext(width, height)
 	dc.SetColor(options.backgroundColor)
 	dc.Clear()
 
 	for gridY := 0; gridY < len(c.Grid); gridY++ {
 		for gridX, cell := range c.Grid[gridY] {
 
+			if !c.Mask.Allowed(gridX, gridY) {
+				// cells outside the mask are not part of the puzzle
+				clearRect(dc, cellOffset+(float64(gridX)*cellWidth), cellOffset+(float64(gridY)*cellHeight), cellWidth, cellHeight)
+				continue
+			}
+
 			dc.DrawRectangle(cellOffset+(float64(gridX)*cellWidth), cellOffset+(float64(gridY)*cellHeight), cellWidth, cellHeight)
 
 			if !cell.Empty() {
@@ -232,7 +284,7 @@ func RenderPNG(c *Crossword, width, height int, opts ...RenderOption) (*gg.Conte
 				placements := c.CellPlacements(gridX, gridY)
 				if placements != nil {
 					clueIDFontSize := cellHeight * 0.25
-					dc.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: clueIDFontSize}))
+					dc.SetFontFace(options.labelFont.face(clueIDFontSize))
 					offset := 0.0
 					for _, pl := range placements {
 						if pl.X == gridX && pl.Y == gridY {
@@ -248,9 +300,9 @@ func RenderPNG(c *Crossword, width, height int, opts ...RenderOption) (*gg.Conte
 
 				dc.SetColor(options.wordColor)
 				if solved || options.solveAll {
-					dc.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: cellHeight * options.wordFontSizePcnt}))
+					dc.SetFontFace(options.font.face(cellHeight * options.wordFontSizePcnt))
 					dc.DrawStringAnchored(
-						strings.ToUpper(cell.String()),
+						strings.ToUpper(c.cellText(gridX, gridY, options.originalSpelling)),
 						cellOffset+float64(gridX)*cellWidth+cellWidth/2,
 						cellOffset+float64(gridY)*cellHeight+cellHeight/2,
 						0.5,
@@ -258,7 +310,15 @@ func RenderPNG(c *Crossword, width, height int, opts ...RenderOption) (*gg.Conte
 					)
 				}
 
-				dc.SetLineWidth(0.3)
+				if cell.Circled {
+					dc.NewSubPath()
+					dc.DrawCircle(
+						cellOffset+float64(gridX)*cellWidth+cellWidth/2,
+						cellOffset+float64(gridY)*cellHeight+cellHeight/2,
+						cellWidth*0.45,
+					)
+				}
+				dc.SetLineWidth(lineWidth)
 				dc.Stroke()
 			} else {
 				dc.SetLineWidth(0)
@@ -269,48 +329,158 @@ func RenderPNG(c *Crossword, width, height int, opts ...RenderOption) (*gg.Conte
 
 	if options.renderClues {
 		dc.SetColor(options.clueColor)
-		dc.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: clueFontSize}))
-		dc.SetLineWidth(0.3)
+		dc.SetFontFace(options.clueFont.face(layout.clueFontSize))
+		dc.SetLineWidth(lineWidth)
+
+		for _, group := range layout.clueGroups {
+			dc.DrawStringAnchored(group.title, group.x, group.y, 0, 0)
+			for _, clue := range group.clues {
+				dc.DrawRectangle(group.x, clue.y+(clue.height/2)-(layout.checkboxSize/2), layout.checkboxSize, layout.checkboxSize)
+				dc.StrokePreserve()
+				if clue.placement.Solved {
+					dc.Fill()
+				}
+				dc.ClearPath()
+				drawStringWrapped(dc, clue.text, group.x+layout.checkboxSpace, clue.y, layout.colWidth-layout.checkboxSpace)
+			}
+		}
+	}
+
+	return dc, nil
+}
+
+// applyRandomSolved marks a random selection of the words as solved if WithRandomSolved is set.
+func applyRandomSolved(c *Crossword, options *renderOpts) {
+	if options.solveRandom {
+		for k := range c.Words {
+			if options.rand.Float64() < 0.5 {
+				c.Words[k].Solved = true
+			}
+		}
+	}
+}
+
+// renderLayout is the position and size of the grid and clues within the output, shared by the renderers.
+type renderLayout struct {
+	cellSize   float64
+	cellOffset float64
+
+	colWidth      float64
+	clueFontSize  float64
+	checkboxSize  float64
+	checkboxSpace float64
+	clueGroups    []clueGroupLayout
+}
+
+type clueGroupLayout struct {
+	title    string
+	vertical bool
+	// x, y is the start of the title's baseline. The checkboxes are aligned with it.
+	x, y  float64
+	clues []clueLayout
+}
+
+type clueLayout struct {
+	placement Placement
+	text      string
+	lines     []string
+	// y is the top of the text and height the height of its lines.
+	y, height float64
+}
+
+// layoutRender positions the grid and, if enabled, finds the largest clue font size which fits the
+// clues in the space beside it. The border, spacing and clue font sizes are multiplied by the scale.
+func layoutRender(c *Crossword, width, height int, scale float64, options *renderOpts) *renderLayout {
+	border, spacing := options.borderWidth*scale, clueSpacing*scale
+	var gridWidth float64
+	if !options.renderClues {
+		gridWidth = float64(width) - 2*border
+	} else {
+		gridWidth = (float64(width) - 3*border) * (1 - options.clueRatio)
+	}
+	requestedGridWidth := gridWidth
+	gridHeight := float64(height) - 2*border
+
+	// ensure the cells are square and the grid fits in both the horizontal and vertical space
+	layout := &renderLayout{
+		cellSize:      min(gridWidth/float64(c.Grid.Width()), gridHeight/float64(c.Grid.Height())),
+		cellOffset:    border,
+		clueFontSize:  25.0 * scale,
+		checkboxSize:  10.0 * scale,
+		checkboxSpace: 15.0 * scale,
+	}
+	if !options.renderClues {
+		return layout
+	}
 
-		colWidth := maxClueWidth
+	// the context is only used to measure text
+	dc := gg.NewContext(1, 1)
+	leftPos := layout.cellOffset + requestedGridWidth + border
+	maxClueWidth := float64(width) - leftPos - border
+
+	// try to find a font size that fits.
+	for layout.clueFontSize > 4*scale {
+		dc.SetFontFace(options.clueFont.face(layout.clueFontSize))
+		layout.checkboxSize = dc.FontHeight() * 0.8
+		layout.checkboxSpace = layout.checkboxSize + spacing
+		clueColumns := 1
 		if options.clueColumns {
-			colWidth = (maxClueWidth - clueSpacing) / 2.0
+			clueColumns = 2
+		}
+		if measureCluesHeight(c, dc, layout.clueFontSize, maxClueWidth, layout.checkboxSpace, clueColumns, border, spacing) <= float64(height)-2*border {
+			break
 		}
+		layout.clueFontSize -= 0.5 * scale
+	}
+	dc.SetFontFace(options.clueFont.face(layout.clueFontSize))
 
-		renderClueGroup := func(title string, vertical bool, xOffset float64, yOffset float64) float64 {
-			offset := yOffset
-			dc.DrawStringAnchored(title, xOffset, offset, 0, 0)
-			offset += clueFontSize
-			for _, w := range c.Words {
-				if w.Vertical == vertical {
-					s := fmt.Sprintf("%s: %s [%s]", w.ClueID(), w.Word.Clue, w.Word.LetterCountStr())
-					height := measureWrappedHeight(dc, s, colWidth-checkboxSpace)
-
-					dc.DrawRectangle(xOffset, offset+(height/2)-(checkboxSize/2), checkboxSize, checkboxSize)
-					dc.StrokePreserve()
-					if w.Solved {
-						dc.Fill()
-					}
-					dc.ClearPath()
-					drawStringWrapped(dc, s, xOffset+checkboxSpace, offset, colWidth-checkboxSpace)
-					offset += height + clueSpacing
-				}
+	layout.colWidth = maxClueWidth
+	if options.clueColumns {
+		layout.colWidth = (maxClueWidth - spacing) / 2.0
+	}
+
+	layoutClueGroup := func(title string, vertical bool, xOffset float64, yOffset float64) float64 {
+		group := clueGroupLayout{title: title, vertical: vertical, x: xOffset, y: yOffset}
+		offset := yOffset + layout.clueFontSize
+		for _, w := range c.Words {
+			if w.Vertical == vertical {
+				s := clueText(w)
+				height := measureWrappedHeight(dc, s, layout.colWidth-layout.checkboxSpace)
+				group.clues = append(group.clues, clueLayout{
+					placement: w,
+					text:      s,
+					lines:     dc.WordWrap(s, layout.colWidth-layout.checkboxSpace),
+					y:         offset,
+					height:    height,
+				})
+				offset += height + spacing
 			}
-			return offset
 		}
+		layout.clueGroups = append(layout.clueGroups, group)
+		return offset
+	}
 
-		if !options.clueColumns {
-			offset := options.borderWidth + clueFontSize
-			offset = renderClueGroup("DOWN", true, leftPos, offset)
-			offset += float64(options.borderWidth)
-			renderClueGroup("ACROSS", false, leftPos, offset)
-		} else {
-			renderClueGroup("DOWN", true, leftPos, options.borderWidth+clueFontSize)
-			renderClueGroup("ACROSS", false, leftPos+colWidth+clueSpacing, options.borderWidth+clueFontSize)
-		}
+	if !options.clueColumns {
+		offset := border + layout.clueFontSize
+		offset = layoutClueGroup("DOWN", true, leftPos, offset)
+		offset += border
+		layoutClueGroup("ACROSS", false, leftPos, offset)
+	} else {
+		layoutClueGroup("DOWN", true, leftPos, border+layout.clueFontSize)
+		layoutClueGroup("ACROSS", false, leftPos+layout.colWidth+spacing, border+layout.clueFontSize)
 	}
+	return layout
+}
 
-	return dc, nil
+// clueText formats the clue of the placement for a clue list.
+func clueText(pl Placement) string {
+	return fmt.Sprintf("%s: %s [%s]", pl.ClueID(), pl.Word.Clue, pl.Word.LetterCountStr())
+}
+
+// clearRect makes the area fully transparent.
+func clearRect(dc *gg.Context, x, y, w, h float64) {
+	rect := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	draw.Draw(dc.Image().(draw.Image), rect, image.Transparent, image.Point{}, draw.Src)
 }
 
 func drawStringWrapped(dc *gg.Context, s string, x, y float64, maxWidth float64) {
@@ -322,14 +492,14 @@ func measureWrappedHeight(dc *gg.Context, s string, maxWidth float64) float64 {
 	return height
 }
 
-func measureCluesHeight(c *Crossword, dc *gg.Context, fontSize float64, maxClueWidth float64, checkboxSpace float64, clueColumns int, borderWidth float64) float64 {
+func measureCluesHeight(c *Crossword, dc *gg.Context, fontSize float64, maxClueWidth float64, checkboxSpace float64, clueColumns int, borderWidth float64, spacing float64) float64 {
 	if clueColumns <= 1 {
 		maxWidth := maxClueWidth - checkboxSpace
 		offset := fontSize // DOWN header
 		offset += fontSize // DOWN header space
 		for _, w := range c.Words {
 			if w.Vertical {
-				offset += measureWrappedHeight(dc, fmt.Sprintf("%s: %s [%s]", w.ClueID(), w.Word.Clue, w.Word.LetterCountStr()), maxWidth) + clueSpacing
+				offset += measureWrappedHeight(dc, clueText(w), maxWidth) + spacing
 			}
 		}
 		offset += borderWidth // middle space
@@ -337,26 +507,26 @@ func measureCluesHeight(c *Crossword, dc *gg.Context, fontSize float64, maxClueW
 		offset += fontSize    // ACROSS header space
 		for _, w := range c.Words {
 			if !w.Vertical {
-				offset += measureWrappedHeight(dc, fmt.Sprintf("%s: %s [%s]", w.ClueID(), w.Word.Clue, w.Word.LetterCountStr()), maxWidth) + clueSpacing
+				offset += measureWrappedHeight(dc, clueText(w), maxWidth) + spacing
 			}
 		}
 		return offset
 	}
 
-	maxWidth := (maxClueWidth - clueSpacing) / 2
+	maxWidth := (maxClueWidth - spacing) / 2
 	textMaxWidth := maxWidth - checkboxSpace
 
 	downHeight := fontSize * 2
 	for _, w := range c.Words {
 		if w.Vertical {
-			downHeight += measureWrappedHeight(dc, fmt.Sprintf("%s: %s [%s]", w.ClueID(), w.Word.Clue, w.Word.LetterCountStr()), textMaxWidth) + clueSpacing
+			downHeight += measureWrappedHeight(dc, clueText(w), textMaxWidth) + spacing
 		}
 	}
 
 	acrossHeight := fontSize * 2
 	for _, w := range c.Words {
 		if !w.Vertical {
-			acrossHeight += measureWrappedHeight(dc, fmt.Sprintf("%s: %s [%s]", w.ClueID(), w.Word.Clue, w.Word.LetterCountStr()), textMaxWidth) + clueSpacing
+			acrossHeight += measureWrappedHeight(dc, clueText(w), textMaxWidth) + spacing
 		}
 	}
 
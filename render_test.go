@@ -0,0 +1,74 @@
+package crossword
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderImage(t *testing.T) {
+	t.Run("same as RenderPNG", func(t *testing.T) {
+		img, err := RenderImage(testCrossword(t), 600, 400, WithClues(true))
+		require.NoError(t, err)
+		dc, err := RenderPNG(testCrossword(t), 600, 400, WithClues(true))
+		require.NoError(t, err)
+		require.Equal(t, dc.Image(), img)
+	})
+	t.Run("scaled", func(t *testing.T) {
+		img, err := RenderImage(testCrossword(t), 600, 400, WithClues(true), WithScale(2))
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 1200, 800), img.Bounds())
+
+		img, err = RenderImage(testCrossword(t), 600, 400, WithScale(1.5))
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 900, 600), img.Bounds())
+	})
+	t.Run("invalid size", func(t *testing.T) {
+		for _, tc := range []struct {
+			width, height int
+			scale         float64
+		}{
+			{600, 400, 0},
+			{600, 400, -1},
+			{0, 0, 1},
+			{600, 1, 0.1},
+		} {
+			img, err := RenderImage(testCrossword(t), tc.width, tc.height, WithScale(tc.scale))
+			require.Error(t, err)
+			require.Nil(t, img)
+		}
+		buff := &bytes.Buffer{}
+		require.Error(t, WriteImage(buff, testCrossword(t), 0, 0, png.Encode))
+		require.Zero(t, buff.Len())
+	})
+}
+
+func TestWriteImage(t *testing.T) {
+	t.Run("png", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		require.NoError(t, WriteImage(buff, testCrossword(t), 600, 400, png.Encode, WithScale(2)))
+		img, err := png.Decode(buff)
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 1200, 800), img.Bounds())
+	})
+	t.Run("jpeg", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		require.NoError(t, WriteImage(buff, testCrossword(t), 600, 400, JPEGEncoder(80)))
+		img, err := jpeg.Decode(buff)
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 600, 400), img.Bounds())
+	})
+	t.Run("encoder error", func(t *testing.T) {
+		failed := errors.New("failed")
+		err := WriteImage(&bytes.Buffer{}, testCrossword(t), 600, 400, func(w io.Writer, img image.Image) error {
+			return failed
+		})
+		require.ErrorIs(t, err, failed)
+	})
+}
@@ -0,0 +1,79 @@
+package crossword
+
+import (
+	"fmt"
+	"slices"
+)
+
+type UnplacedReason int
+
+const (
+	// UnplacedDuplicate means the same word was already in the crossword.
+	UnplacedDuplicate UnplacedReason = iota + 1
+	// UnplacedNoSharedLetters means the word had no letters in common with any placed word.
+	UnplacedNoSharedLetters
+	// UnplacedCollision means the word could intersect the grid but every such placement
+	// collided with, or was directly adjacent to, another word.
+	UnplacedCollision
+	// UnplacedOverflow means every placement intersecting the grid would extend past its edges
+	// or use a cell outside the mask.
+	UnplacedOverflow
+)
+
+func (r UnplacedReason) String() string {
+	switch r {
+	case UnplacedDuplicate:
+		return "duplicate"
+	case UnplacedNoSharedLetters:
+		return "no shared letters"
+	case UnplacedCollision:
+		return "collision"
+	case UnplacedOverflow:
+		return "overflow"
+	}
+	return fmt.Sprintf("UnplacedReason(%d)", int(r))
+}
+
+type UnplacedWord struct {
+	Word   Word
+	Reason UnplacedReason
+}
+
+// GenerationReport describes the outcome of generating a crossword.
+type GenerationReport struct {
+	// Unplaced lists the words that are not in the crossword along with the reason they
+	// could not be placed when they were considered.
+	Unplaced []UnplacedWord
+}
+
+// sortUnplaced orders the unplaced words to match the order they were given in.
+func (r *GenerationReport) sortUnplaced(words []Word) {
+	slices.SortStableFunc(r.Unplaced, func(a, b UnplacedWord) int {
+		return wordIndex(words, a.Word) - wordIndex(words, b.Word)
+	})
+}
+
+func wordIndex(words []Word, word Word) int {
+	return slices.IndexFunc(words, func(w Word) bool {
+		return w.Word == word.Word && w.Clue == word.Clue
+	})
+}
+
+// noPlacementReason determines why there were no placements suggested for the word.
+func (g *Generator) noPlacementReason(word Word) UnplacedReason {
+	chars := word.chars()
+	if len(chars) > max(g.width, g.height) {
+		return UnplacedOverflow
+	}
+	for charIdx := range len(chars) {
+		for y := range g.height {
+			for x := range g.width {
+				// since there were no placements any intersection must be out of bounds
+				if g.grid[y][x].Char == chars[charIdx] {
+					return UnplacedOverflow
+				}
+			}
+		}
+	}
+	return UnplacedNoSharedLetters
+}
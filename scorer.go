@@ -0,0 +1,102 @@
+package crossword
+
+// Scorer scores a candidate placement of a word in the grid. The placement with the highest
+// score is chosen and its score added to the crossword's TotalScore.
+//
+// Placements that collide with existing words or do not intersect any of them are rejected
+// before being scored, so a Scorer only needs to rank valid placements.
+type Scorer interface {
+	Score(grid Grid, pl Placement) int
+}
+
+// ScorerFunc allows an ordinary function to be used as a Scorer.
+type ScorerFunc func(grid Grid, pl Placement) int
+
+func (f ScorerFunc) Score(grid Grid, pl Placement) int {
+	return f(grid, pl)
+}
+
+// IntersectionScorer scores a placement as 1 + the number of words it crosses.
+// This is the default scorer.
+type IntersectionScorer struct{}
+
+func (IntersectionScorer) Score(grid Grid, pl Placement) int {
+	return 1 + intersections(grid, pl)
+}
+
+// CompactnessScorer prefers placements that grow the area occupied by the existing
+// words the least, then those with the most intersections.
+type CompactnessScorer struct{}
+
+func (CompactnessScorer) Score(grid Grid, pl Placement) int {
+	minX, minY, maxX, maxY := grid.Width(), grid.Height(), -1, -1
+	for y := range grid {
+		for x, cell := range grid[y] {
+			if !cell.Empty() {
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		return 1
+	}
+	endX, endY := pl.end()
+	before := (maxX - minX + 1) * (maxY - minY + 1)
+	after := (max(maxX, endX) - min(minX, pl.X) + 1) * (max(maxY, endY) - min(minY, pl.Y) + 1)
+
+	// intersections can never exceed the word length so they only break ties between equal areas
+	return 1 + intersections(grid, pl) - (after-before)*(pl.Word.Len()+1)
+}
+
+// DensityScorer prefers placements that cross many words and run close alongside others,
+// producing tightly interlocked layouts.
+type DensityScorer struct{}
+
+func (DensityScorer) Score(grid Grid, pl Placement) int {
+	return 1 + 2*intersections(grid, pl) + nearbyLetters(grid, pl)
+}
+
+// SparseScorer prefers placements that cross as few words as possible and keep away
+// from other words, producing open layouts that are easier to solve.
+type SparseScorer struct{}
+
+func (SparseScorer) Score(grid Grid, pl Placement) int {
+	return 1 - 2*(intersections(grid, pl)-1) - nearbyLetters(grid, pl)
+}
+
+// intersections counts the cells of the placement that are already occupied by the same letter.
+func intersections(grid Grid, pl Placement) int {
+	count := 0
+	for charIdx, char := range pl.Word.chars() {
+		x, y := pl.cell(charIdx)
+		if grid[y][x].Char == char {
+			count++
+		}
+	}
+	return count
+}
+
+// nearbyLetters counts occupied cells within two cells either side of the placement
+// (excluding the intersections themselves).
+func nearbyLetters(grid Grid, pl Placement) int {
+	length := pl.Word.Len()
+	count := 0
+	for charIdx := -2; charIdx < length+2; charIdx++ {
+		for offset := -2; offset <= 2; offset++ {
+			if offset == 0 && charIdx >= 0 && charIdx < length {
+				continue
+			}
+			x, y := pl.cell(charIdx)
+			if pl.Vertical {
+				x += offset
+			} else {
+				y += offset
+			}
+			if y >= 0 && y < grid.Height() && x >= 0 && x < grid.Width() && !grid[y][x].Empty() {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,52 @@
+package crossword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScorers(t *testing.T) {
+	// FOOBAR
+	// ###U##
+	// ###D##
+	g := NewRectGenerator(8, 6)
+	g.placeWord(Placement{Word: word("FOOBAR")})
+	g.placeWord(Placement{Word: word("BUD"), X: 3, Vertical: true})
+
+	oneCrossing := Placement{Word: word("RAT"), X: 5, Vertical: true}
+	belowTopWord := Placement{Word: word("DOG"), X: 3, Y: 2}
+	extendsArea := Placement{Word: word("FIGHT"), Vertical: true}
+
+	tests := []struct {
+		name   string
+		scorer Scorer
+		pl     Placement
+		want   int
+	}{
+		{name: "intersection", scorer: IntersectionScorer{}, pl: oneCrossing, want: 2},
+		{name: "compactness within area", scorer: CompactnessScorer{}, pl: oneCrossing, want: 2},
+		{name: "compactness growing area", scorer: CompactnessScorer{}, pl: extendsArea, want: 2 - (6*5-6*3)*6},
+		{name: "density beside top word", scorer: DensityScorer{}, pl: oneCrossing, want: 1 + 2 + 4},
+		{name: "density below top word", scorer: DensityScorer{}, pl: belowTopWord, want: 1 + 2 + 6},
+		{name: "sparse beside top word", scorer: SparseScorer{}, pl: oneCrossing, want: 1 - 4},
+		{name: "sparse below top word", scorer: SparseScorer{}, pl: belowTopWord, want: 1 - 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Greater(t, g.scorePlacement(tt.pl), 1, "placement should be valid")
+			require.EqualValues(t, tt.want, tt.scorer.Score(g.grid, tt.pl))
+		})
+	}
+}
+
+func TestGenerator_Generate_withScorer(t *testing.T) {
+	// prefer the rightmost placement rather than the one with the most intersections
+	rightmost := ScorerFunc(func(grid Grid, pl Placement) int {
+		return pl.X
+	})
+	cw := NewGenerator(6).Generate([]Word{{Word: "foobar"}, {Word: "oaf"}}, 1, WithScorer(rightmost))
+	require.Len(t, cw.Words, 2)
+	require.EqualValues(t, Placement{ID: 2, Word: Word{Word: "OAF", LettersCounts: []int{3}}, X: 2, Vertical: true}, cw.Words[1])
+	require.EqualValues(t, 2, cw.TotalScore)
+}
@@ -0,0 +1,239 @@
+package crossword
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	defaultNodeBudget = 2000
+	defaultBranching  = 3
+)
+
+// WithNodeBudget limits the number of search nodes visited by the SearchGenerator from each start
+// word. Once the budget is spent the best layout found so far is used. For the BlockGenerator
+// it limits the nodes visited while filling each block pattern.
+func WithNodeBudget(nodes int) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.nodeBudget = nodes
+	}
+}
+
+// WithBranching limits the SearchGenerator to the given number of highest scoring placements
+// for each word.
+func WithBranching(n int) GeneratorOpt {
+	return func(opts *generatorOpts) {
+		opts.branching = n
+	}
+}
+
+func Search(gridSize int, words []Word, attempts int, opts ...GeneratorOpt) *Crossword {
+	return NewSearchGenerator(gridSize).Generate(words, attempts, opts...)
+}
+
+func NewSearchGenerator(gridSize int) *SearchGenerator {
+	return NewRectSearchGenerator(gridSize, gridSize)
+}
+
+// NewRectSearchGenerator creates a search generator for a grid with the given number of columns (width) and rows (height).
+func NewRectSearchGenerator(width, height int) *SearchGenerator {
+	return &SearchGenerator{width: width, height: height}
+}
+
+var _ LayoutGenerator = &SearchGenerator{}
+
+// SearchGenerator performs a depth-first search over the possible placements of the words,
+// backtracking when a choice leaves fewer words placed than the best layout found so far.
+// It is slower than the greedy Generator but will often place every word in lists where the
+// greedy approach fails. As with the Generator, each attempt starts from each of the words in
+// turn and subsequent attempts use a random ordering of the words.
+type SearchGenerator struct {
+	width  int
+	height int
+}
+
+func (s *SearchGenerator) Generate(words []Word, attempts int, opts ...GeneratorOpt) *Crossword {
+	cw, _ := s.GenerateContext(context.Background(), words, attempts, opts...)
+	return cw
+}
+
+// GenerateContext generates a crossword, checking the context before visiting each search node.
+// The result and error are the same as Generator.GenerateContext.
+func (s *SearchGenerator) GenerateContext(ctx context.Context, words []Word, attempts int, opts ...GeneratorOpt) (*Crossword, error) {
+	return generate(ctx, s.width, s.height, words, attempts, resolveGeneratorOptions(opts), func(worker int) attemptRunner {
+		return &searchWorker{width: s.width, height: s.height}
+	})
+}
+
+// searchWorker holds the state of a single search.
+type searchWorker struct {
+	width  int
+	height int
+
+	options *generatorOpts
+	nodes   int
+	pruned  int
+	best    *Crossword
+}
+
+// runAttempt searches from each possible start word in turn, with the node budget applying to each.
+func (w *searchWorker) runAttempt(ctx context.Context, words []Word, options *generatorOpts) (*Crossword, bool) {
+	w.options = options
+	w.best = nil
+
+	starts := len(words)
+	if len(options.pinned) > 0 {
+		// the pinned words are always the start of the search
+		starts = 1
+	}
+	for startWord := range starts {
+		// the first greedy pass always completes so there is a result
+		if startWord > 0 && ctx.Err() != nil {
+			return w.best, true
+		}
+
+		// the greedy layout is a good initial bound for the search, and ensures the result
+		// is never worse than the Generator's.
+		greedy := NewRectGenerator(w.width, w.height)
+		greedy.runPass(words, startWord, options)
+		if cw := greedy.crossword(); w.best == nil || cw.betterThan(w.best) {
+			w.best = cw
+		}
+		if len(w.best.Report.Unplaced) == 0 {
+			break
+		}
+
+		board := NewRectGenerator(w.width, w.height)
+		board.mask = options.mask
+		for _, pin := range options.pinned {
+			board.placeWord(pin)
+		}
+		open := slices.Clone(words)
+		var skipped []Word
+		if len(options.pinned) == 0 {
+			if first := board.firstPlacement(words[startWord]); board.fits(first) {
+				board.placeWord(first)
+			} else {
+				skipped = append(skipped, first.Word)
+			}
+			open = slices.Delete(open, startWord, startWord+1)
+		}
+
+		w.nodes = 0
+		w.search(ctx, board, open, skipped)
+		if len(w.best.Report.Unplaced) == 0 {
+			break
+		}
+	}
+	return w.best, ctx.Err() != nil
+}
+
+// search places the most constrained of the open words in each of its best positions in turn, then
+// tries leaving it out. It returns true once the search should stop entirely.
+func (w *searchWorker) search(ctx context.Context, board *Generator, open []Word, skipped []Word) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	w.nodes++
+
+	// no layout below this node can place more words than the best so far
+	if w.best != nil && !board.couldBeat(w.best, open, skipped) {
+		w.pruned++
+		return false
+	}
+
+	next := -1
+	var nextCandidates []candidate
+	for k, word := range open {
+		if board.hasWord(word) {
+			continue
+		}
+		placements := board.suggestPlacements(word)
+		if placements == nil {
+			continue
+		}
+		candidates := board.candidates(placements, w.options.scorer)
+		if len(candidates) > 0 && (next == -1 || len(candidates) < len(nextCandidates)) {
+			next, nextCandidates = k, candidates
+		}
+	}
+	if next == -1 || w.nodes >= w.nodeBudget() {
+		w.record(board, append(slices.Clone(skipped), open...))
+		return w.nodes >= w.nodeBudget() || len(w.best.Report.Unplaced) == 0
+	}
+
+	word := open[next]
+	rest := slices.Delete(slices.Clone(open), next, next+1)
+	for _, c := range nextCandidates[:min(len(nextCandidates), w.branching())] {
+		child := board.clone()
+		child.placeWord(c.Placement)
+		child.totalScore += c.score
+		if w.search(ctx, child, rest, skipped) {
+			return true
+		}
+	}
+	return w.search(ctx, board, rest, append(slices.Clone(skipped), word))
+}
+
+// record completes the layout by placing any remaining words where possible and keeps it if
+// it is better than the best layout so far.
+func (w *searchWorker) record(board *Generator, unplaced []Word) {
+	leaf := board.clone()
+	for _, word := range unplaced {
+		if reason := leaf.placeBest(word, w.options); reason != 0 {
+			leaf.unplaced = append(leaf.unplaced, UnplacedWord{Word: word, Reason: reason})
+		}
+	}
+	if cw := leaf.crossword(); w.best == nil || cw.betterThan(w.best) {
+		w.best = cw
+	}
+}
+
+func (w *searchWorker) nodeBudget() int {
+	if w.options.nodeBudget > 0 {
+		return w.options.nodeBudget
+	}
+	return defaultNodeBudget
+}
+
+func (w *searchWorker) branching() int {
+	if w.options.branching > 0 {
+		return w.options.branching
+	}
+	return defaultBranching
+}
+
+// couldBeat checks if placing every open and skipped word that could still be placed would result
+// in a better coverage than the given crossword. Skipped words are counted because they are tried
+// again when the layout is recorded. A word can only be placed later if it fits the grid now or
+// shares a letter with another remaining word, since placing words never frees up a position.
+func (g *Generator) couldBeat(cw *Crossword, open []Word, skipped []Word) bool {
+	remaining := slices.Concat(open, skipped)
+	optimistic := &Crossword{Words: slices.Clone(g.placedWords)}
+	for _, word := range remaining {
+		if g.hasWord(word) {
+			continue
+		}
+		if g.canPlace(word) || slices.ContainsFunc(remaining, func(other Word) bool {
+			return other.Word != word.Word && !g.hasWord(other) && sharesLetter(word, other)
+		}) {
+			optimistic.Words = append(optimistic.Words, Placement{Word: word})
+		}
+	}
+	required, coverage := optimistic.coverage()
+	bestRequired, bestCoverage := cw.coverage()
+	return required > bestRequired || (required == bestRequired && coverage > bestCoverage)
+}
+
+// canPlace checks if the word has at least one valid position in the current grid.
+func (g *Generator) canPlace(word Word) bool {
+	return slices.ContainsFunc(g.suggestPlacements(word), func(pl Placement) bool {
+		return g.scorePlacement(pl) >= 2
+	})
+}
+
+func sharesLetter(a, b Word) bool {
+	return slices.ContainsFunc(a.chars(), func(char rune) bool {
+		return slices.Contains(b.chars(), char)
+	})
+}
@@ -0,0 +1,79 @@
+package crossword
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchGenerator_Generate(t *testing.T) {
+	words := func() []Word {
+		return []Word{{Word: "hhfd"}, {Word: "hfa"}, {Word: "cgbg"}, {Word: "abhh"}, {Word: "geh"}}
+	}
+
+	greedy := NewGenerator(5).Generate(words(), 1)
+	require.Len(t, greedy.Words, 3, "greedy generator should not be able to place all words")
+
+	searched := NewSearchGenerator(5).Generate(words(), 1)
+	require.Len(t, searched.Words, 5)
+	require.Empty(t, searched.Report.Unplaced)
+}
+
+func TestSearchGenerator_GenerateContext(t *testing.T) {
+	t.Run("node budget still returns a layout", func(t *testing.T) {
+		cw, err := NewSearchGenerator(5).GenerateContext(
+			context.Background(),
+			[]Word{{Word: "hhfd"}, {Word: "hfa"}, {Word: "cgbg"}, {Word: "abhh"}, {Word: "geh"}, {Word: "xyz"}},
+			1,
+			WithNodeBudget(1),
+		)
+		require.NoError(t, err)
+		require.NotEmpty(t, cw.Words)
+		require.EqualValues(t, UnplacedNoSharedLetters, cw.Report.Unplaced[len(cw.Report.Unplaced)-1].Reason)
+	})
+	t.Run("duplicates are not placed", func(t *testing.T) {
+		cw, err := NewSearchGenerator(5).GenerateContext(context.Background(), []Word{{Word: "food"}, {Word: "food"}, {Word: "fud"}}, 1)
+		require.NoError(t, err)
+		require.Len(t, cw.Words, 2)
+		require.EqualValues(t, []UnplacedWord{{Word: Word{Word: "FOOD", LettersCounts: []int{4}}, Reason: UnplacedDuplicate}}, cw.Report.Unplaced)
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewSearchGenerator(5).GenerateContext(ctx, []Word{{Word: "food"}, {Word: "fud"}}, 1)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSearchGenerator_couldBeat(t *testing.T) {
+	words := []Word{{Word: "food"}, {Word: "fud"}, {Word: "duff"}, {Word: "dough"}, {Word: "huge"}, {Word: "edge"}, {Word: "ogre"}, {Word: "gruff"}, {Word: "xyz"}}
+	options := resolveGeneratorOptions([]GeneratorOpt{WithNodeBudget(100000)})
+	prepareWords(words, options)
+
+	t.Run("words that cannot be placed are not counted", func(t *testing.T) {
+		board := NewGenerator(10)
+		board.placeWord(board.firstPlacement(words[0]))
+		best := &Crossword{Words: []Placement{{Word: words[0]}, {Word: words[1]}}}
+		require.True(t, board.couldBeat(best, words[1:3], nil))
+		require.True(t, board.couldBeat(best, words[1:2], words[2:3]), "skipped words are tried again")
+		require.False(t, board.couldBeat(best, words[1:2], words[8:]), "xyz has no position in the grid")
+	})
+	t.Run("branches are pruned", func(t *testing.T) {
+		w := &searchWorker{width: 10, height: 10, options: options}
+		board := NewGenerator(10)
+		board.placeWord(board.firstPlacement(words[0]))
+		w.search(context.Background(), board, words[1:], nil)
+
+		// xyz can never be placed, so without the bound the search would visit every branch
+		require.Positive(t, w.pruned)
+		require.Len(t, w.best.Words, len(words)-1)
+	})
+}
+
+func TestLayoutGenerator(t *testing.T) {
+	for _, g := range []LayoutGenerator{NewGenerator(5), NewSearchGenerator(5)} {
+		cw := g.Generate([]Word{{Word: "food"}, {Word: "fud"}}, 1, WithPinned(Placement{Word: word("dug"), X: 0, Y: 3}))
+		require.Len(t, cw.Words, 3)
+	}
+}
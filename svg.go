@@ -0,0 +1,163 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// RenderSVG writes the crossword as an SVG image, laid out the same way as RenderImage and using
+// the same options. The elements have IDs so they can be styled and scripted:
+//
+//   - cells are groups with the ID cell-{x}-{y} and the class "cell" plus "block" or "letter", with
+//     data-clues listing the IDs of the clues they belong to
+//   - labels have the ID label-{clue ID} e.g. label-A1, and letters the ID letter-{x}-{y}
+//   - clues are groups with the ID clue-{clue ID} and the class "clue", plus "solved" if the word is solved
+//
+// Cells outside the Mask are left transparent. The text is laid out using the fonts set by WithFont,
+// WithLabelFont and WithClueFont, so the SVG is only rendered as intended where they are available.
+func RenderSVG(w io.Writer, c *Crossword, width, height int, opts ...RenderOption) error {
+	options := resolveRenderOptions(opts...)
+	if err := options.loadFonts(); err != nil {
+		return err
+	}
+	applyRandomSolved(c, options)
+	layout := layoutRender(c, width, height, 1, options)
+	cellSize, cellOffset := layout.cellSize, layout.cellOffset
+	letterFamily, labelFamily, clueFamily, fontFaces := options.cssFonts()
+
+	// the context is only used to measure text
+	dc := gg.NewContext(1, 1)
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	if fontFaces != "" {
+		fmt.Fprintf(out, "<style>\n%s</style>\n", fontFaces)
+	}
+
+	background := fmt.Sprintf(`<rect id="background" width="%d" height="%d"%s`, width, height, svgColor("fill", options.backgroundColor))
+	if c.Mask != nil {
+		// cells outside the mask are cut out of the background
+		fmt.Fprintf(out, `<mask id="puzzle-mask"><rect width="%d" height="%d" fill="white"/>`, width, height)
+		for y := range c.Grid {
+			for x := range c.Grid[y] {
+				if !c.Mask.Allowed(x, y) {
+					fmt.Fprintf(out, `<rect x="%s" y="%s" width="%s" height="%s" fill="black"/>`, svgNum(cellOffset+float64(x)*cellSize), svgNum(cellOffset+float64(y)*cellSize), svgNum(cellSize), svgNum(cellSize))
+				}
+			}
+		}
+		fmt.Fprintf(out, "</mask>\n")
+		background += ` mask="url(#puzzle-mask)"`
+	}
+	fmt.Fprintf(out, "%s/>\n", background)
+
+	fmt.Fprintf(out, `<g id="grid" font-family="%s">`+"\n", svgEscape(letterFamily))
+	labelFontSize := cellSize * 0.25
+	wordFontSize := cellSize * options.wordFontSizePcnt
+	dc.SetFontFace(options.font.face(wordFontSize))
+	wordFontHeight := dc.FontHeight()
+	for gridY := range c.Grid {
+		for gridX, cell := range c.Grid[gridY] {
+			if !c.Mask.Allowed(gridX, gridY) {
+				continue
+			}
+			cellX, cellY := cellOffset+float64(gridX)*cellSize, cellOffset+float64(gridY)*cellSize
+			if cell.Empty() {
+				fmt.Fprintf(out, `<g id="cell-%d-%d" class="cell block"/>`+"\n", gridX, gridY)
+				continue
+			}
+
+			placements := c.CellPlacements(gridX, gridY)
+			clueIDs := make([]string, len(placements))
+			for k, pl := range placements {
+				clueIDs[k] = pl.ClueID()
+			}
+			fmt.Fprintf(out, `<g id="cell-%d-%d" class="cell letter" data-clues="%s">`, gridX, gridY, svgEscape(strings.Join(clueIDs, " ")))
+			fmt.Fprintf(out, `<rect x="%s" y="%s" width="%s" height="%s"%s%s stroke-width="0.3"/>`, svgNum(cellX), svgNum(cellY), svgNum(cellSize), svgNum(cellSize), svgColor("fill", options.wordBackgroundColor), svgColor("stroke", options.wordColor))
+
+			var solved bool
+			offset := 0.0
+			for _, pl := range placements {
+				if pl.X == gridX && pl.Y == gridY {
+					fmt.Fprintf(out, `<text id="label-%s" class="label" x="%s" y="%s" font-family="%s" font-size="%s"%s>%s</text>`, svgEscape(pl.ClueID()), svgNum(cellX), svgNum(cellY+labelFontSize+offset), svgEscape(labelFamily), svgNum(labelFontSize), svgColor("fill", options.labelColor), svgEscape(pl.ClueID()))
+					offset = cellSize - (labelFontSize * 1.4)
+				}
+				if pl.Solved || slices.Contains(pl.Word.CharacterHints, cell.CharIdx) {
+					solved = true
+				}
+			}
+			if solved || options.solveAll {
+				fmt.Fprintf(out, `<text id="letter-%d-%d" class="letter" x="%s" y="%s" font-size="%s" text-anchor="middle"%s>%s</text>`, gridX, gridY, svgNum(cellX+cellSize/2), svgNum(cellY+cellSize/2+wordFontHeight/2), svgNum(wordFontSize), svgColor("fill", options.wordColor), svgEscape(strings.ToUpper(c.cellText(gridX, gridY, options.originalSpelling))))
+			}
+			if cell.Circled {
+				fmt.Fprintf(out, `<circle cx="%s" cy="%s" r="%s" fill="none"%s stroke-width="0.3"/>`, svgNum(cellX+cellSize/2), svgNum(cellY+cellSize/2), svgNum(cellSize*0.45), svgColor("stroke", options.wordColor))
+			}
+			fmt.Fprintf(out, "</g>\n")
+		}
+	}
+	fmt.Fprintf(out, "</g>\n")
+
+	if options.renderClues {
+		dc.SetFontFace(options.clueFont.face(layout.clueFontSize))
+		fmt.Fprintf(out, `<g id="clues" font-family="%s" font-size="%s"%s>`+"\n", svgEscape(clueFamily), svgNum(layout.clueFontSize), svgColor("fill", options.clueColor))
+		for _, group := range layout.clueGroups {
+			fmt.Fprintf(out, `<g id="clues-%s" class="clue-group">`+"\n", strings.ToLower(group.title))
+			fmt.Fprintf(out, `<text class="clue-title" x="%s" y="%s">%s</text>`+"\n", svgNum(group.x), svgNum(group.y), svgEscape(group.title))
+			for _, clue := range group.clues {
+				class := "clue"
+				fill := ` fill="none"`
+				if clue.placement.Solved {
+					class += " solved"
+					fill = svgColor("fill", options.clueColor)
+				}
+				fmt.Fprintf(out, `<g id="clue-%s" class="%s">`, svgEscape(clue.placement.ClueID()), class)
+				fmt.Fprintf(out, `<rect class="checkbox" x="%s" y="%s" width="%s" height="%s"%s%s stroke-width="0.3"/>`, svgNum(group.x), svgNum(clue.y+(clue.height/2)-(layout.checkboxSize/2)), svgNum(layout.checkboxSize), svgNum(layout.checkboxSize), fill, svgColor("stroke", options.clueColor))
+				fmt.Fprintf(out, `<text x="%s">`, svgNum(group.x+layout.checkboxSpace))
+				for k, line := range clue.lines {
+					// each line is positioned by its baseline, one line below the top of the text
+					fmt.Fprintf(out, `<tspan x="%s" y="%s">%s</tspan>`, svgNum(group.x+layout.checkboxSpace), svgNum(clue.y+float64(k+1)*dc.FontHeight()), svgEscape(line))
+				}
+				fmt.Fprintf(out, "</text></g>\n")
+			}
+			fmt.Fprintf(out, "</g>\n")
+		}
+		fmt.Fprintf(out, "</g>\n")
+	}
+	fmt.Fprintf(out, "</svg>\n")
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// svgColor formats the color as an attribute, with a separate opacity if it is not opaque.
+func svgColor(attr string, cl color.Color) string {
+	r, g, b, a := cl.RGBA()
+	if a == 0 {
+		return fmt.Sprintf(` %s="none"`, attr)
+	}
+	// the components are premultiplied by alpha
+	value := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, r*0xff/a, g*0xff/a, b*0xff/a)
+	if a < 0xffff {
+		value += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNum(float64(a)/0xffff))
+	}
+	return value
+}
+
+// svgNum formats the number with at most two decimal places.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+func svgEscape(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s))
+	return b.String()
+}
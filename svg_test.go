@@ -0,0 +1,67 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSVG(t *testing.T) {
+	cw := testCrossword(t)
+	cw.Words[0].Solved = true
+	cw.Words[0].Word.Clue = "Fruit & <veg>"
+	cw.Grid[0][0].Circled = true
+
+	buff := &bytes.Buffer{}
+	require.NoError(t, RenderSVG(buff, cw, 1200, 600, WithClues(true), WithBorder(10)))
+	svg := buff.String()
+
+	// the output is well-formed
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	require.Contains(t, svg, `<g id="cell-0-0" class="cell letter" data-clues="A1`)
+	require.Contains(t, svg, `id="label-A1"`)
+	require.Contains(t, svg, `id="letter-0-0"`)
+	require.Contains(t, svg, `<circle`)
+	require.Contains(t, svg, `<g id="clue-A1" class="clue solved">`)
+	require.Contains(t, svg, `Fruit &amp; &lt;veg&gt;`)
+	require.Contains(t, svg, `<g id="clues-down"`)
+	require.Contains(t, svg, `<g id="clues-across"`)
+	require.NotContains(t, svg, `mask=`)
+
+	// unsolved letters are hidden unless every word is solved
+	hidden := ""
+	for y := range cw.Grid {
+		for x, cell := range cw.Grid[y] {
+			if hidden == "" && !cell.Empty() && !cw.revealed(x, y) {
+				hidden = fmt.Sprintf(`id="letter-%d-%d"`, x, y)
+			}
+		}
+	}
+	require.NotEmpty(t, hidden)
+	require.NotContains(t, svg, hidden)
+	buff.Reset()
+	require.NoError(t, RenderSVG(buff, cw, 600, 600, WithAllSolved(true)))
+	require.Contains(t, buff.String(), hidden)
+	require.NotContains(t, buff.String(), `id="clues"`)
+
+	t.Run("mask", func(t *testing.T) {
+		cw.Mask = MaskFromString(strings.Repeat("#", cw.Grid.Width()-1) + "\n" + strings.Repeat(strings.Repeat("#", cw.Grid.Width())+"\n", cw.Grid.Height()-1))
+		buff.Reset()
+		require.NoError(t, RenderSVG(buff, cw, 600, 600))
+		require.Contains(t, buff.String(), `mask="url(#puzzle-mask)"`)
+		require.NotContains(t, buff.String(), fmt.Sprintf(`id="cell-%d-0"`, cw.Grid.Width()-1))
+	})
+}
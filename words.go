@@ -1,10 +1,13 @@
 package crossword
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // WordsFromCSV creates a word list from a CSV with 2 columns (word, clue)
@@ -19,7 +22,35 @@ func WordsFromCSV(f io.Reader) ([]Word, error) {
 		if len(r) != 2 {
 			return nil, fmt.Errorf("csv should have exactly 2 columns (word, clue)")
 		}
-		words = append(words, Word{Word: strings.TrimSpace(r[0]), LettersCounts: []int{len(r[0])}, Clue: strings.TrimSpace(r[1])})
+		words = append(words, Word{Word: strings.TrimSpace(r[0]), LettersCounts: []int{utf8.RuneCountInString(r[0])}, Clue: strings.TrimSpace(r[1])})
 	}
 	return words, nil
 }
+
+// DictionaryFromReader creates a dictionary for Autofill from lines of "word;score", the format used
+// by most published scored word lists. The score is optional and defaults to zero. Blank lines are skipped.
+func DictionaryFromReader(f io.Reader) ([]DictionaryEntry, error) {
+	entries := []DictionaryEntry{}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		word, scoreStr, hasScore := strings.Cut(scanner.Text(), ";")
+		entry := DictionaryEntry{Word: Word{Word: strings.TrimSpace(word)}}
+		if hasScore {
+			score, err := strconv.Atoi(strings.TrimSpace(scoreStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid score on line %d: %w", line, err)
+			}
+			entry.Score = score
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	return entries, nil
+}
@@ -47,3 +47,12 @@ func TestWordsFromCSV(t *testing.T) {
 		})
 	}
 }
+
+func TestDictionaryFromReader(t *testing.T) {
+	entries, err := DictionaryFromReader(strings.NewReader("foo;50\n\nbar\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []DictionaryEntry{{Word: Word{Word: "foo"}, Score: 50}, {Word: Word{Word: "bar"}}}, entries)
+
+	_, err = DictionaryFromReader(strings.NewReader("foo;high"))
+	assert.Error(t, err)
+}